@@ -0,0 +1,100 @@
+package goavl
+
+import "errors"
+
+// ErrNotSorted is returned when the entries passed to a bulk load operation
+// are not in strictly ascending order according to the tree's comparator.
+var ErrNotSorted = errors.New("goavl: keys are not in ascending order")
+
+// buildFromSorted replaces the contents of the tree with n entries returned by next.
+// The entries must be in strictly ascending order. The resulting tree is perfectly balanced.
+// The tree is built separately, so if next returns an error, or the entries are not sorted,
+// the tree is left intact, and journal and observers see the changes only on success.
+// Returns ErrCapacityExceeded, if n exceeds the capacity,
+// or ErrCountOverflow, if n elements don't fit into children counts.
+// Time complexity: O(n).
+func (t *Tree[K, V, Cmp]) buildFromSorted(n int, next func() (K, V, error)) error {
+	if err := t.checkBulkLen(n); err != nil {
+		return err
+	}
+	s := t.newScratch()
+	b := sortedBuilder[K, V, Cmp]{t: s, next: next}
+	root := b.build(n)
+	if b.err != nil {
+		return b.err
+	}
+	s.setRoot(root)
+	s.length = n
+	t.replaceWith(s)
+	return nil
+}
+
+// checkBulkLen returns an error, if the tree can't hold n elements.
+func (t *Tree[K, V, Cmp]) checkBulkLen(n int) error {
+	if t.options.capacity > 0 && n > t.options.capacity {
+		return ErrCapacityExceeded
+	}
+	if t.options.countChildren && n > maxCountedLen {
+		return ErrCountOverflow
+	}
+	return nil
+}
+
+// newScratch returns an empty tree with the comparator, the allocator and the options of t,
+// but without journal, observers and eviction callback, as its mutations are not visible to the user.
+// It has no capacity, the callers check the resulting length with checkBulkLen.
+// Its nodes are moved to t by replaceWith.
+func (t *Tree[K, V, Cmp]) newScratch() *Tree[K, V, Cmp] {
+	options := t.options
+	options.journal, options.observers, options.onEvict, options.checkCmp = nil, nil, nil, false
+	options.capacity = 0
+	s := newWithOptions[K, V](t.cmp, options)
+	s.lc = t.lc
+	s.nextID = t.nextID
+	return s
+}
+
+// replaceWith replaces the contents of t with the nodes of s created by t.newScratch.
+// Observers are notified about the deletion of the old elements and the insertion of the new ones.
+func (t *Tree[K, V, Cmp]) replaceWith(s *Tree[K, V, Cmp]) {
+	t.Clear()
+	t.nextID = max2(t.nextID, s.nextID)
+	t.setBuiltRoot(s.root, s.length)
+}
+
+type sortedBuilder[K, V any, Cmp func(a, b K) int] struct {
+	t    *Tree[K, V, Cmp]
+	next func() (K, V, error)
+	prev location[K, V]
+	err  error
+}
+
+// build reads n entries in order and returns the root of a balanced subtree built from them.
+func (b *sortedBuilder[K, V, Cmp]) build(n int) location[K, V] {
+	if n <= 0 || b.err != nil {
+		return location[K, V]{}
+	}
+	leftCount := (n - 1) / 2
+	left := b.build(leftCount)
+	if b.err != nil {
+		return location[K, V]{}
+	}
+	k, v, err := b.next()
+	if err != nil {
+		b.err = err
+		return location[K, V]{}
+	}
+	if !b.prev.isNil() && b.t.cmp(b.prev.key(), k) >= 0 {
+		b.err = ErrNotSorted
+		return location[K, V]{}
+	}
+	loc := b.t.lc.new(k, v)
+	loc.setID(b.t.newLocationID())
+	b.prev = loc
+	right := b.build(n - leftCount - 1)
+	loc.setLeft(left)
+	loc.setRight(right)
+	loc.recalcHeight()
+	b.t.recalcAugments(loc)
+	return loc
+}
@@ -0,0 +1,71 @@
+package goavl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCapacityExceeded is returned by bulk load operations if the number of entries
+// exceeds the capacity set by WithCapacity.
+var ErrCapacityExceeded = errors.New("goavl: capacity exceeded")
+
+// EvictPolicy defines which element is evicted from a full tree.
+type EvictPolicy int8
+
+const (
+	// EvictMax evicts the maximum element, so that the tree keeps the smallest keys.
+	EvictMax EvictPolicy = iota
+	// EvictMin evicts the minimum element, so that the tree keeps the largest keys.
+	EvictMin
+)
+
+// WithCapacity limits the number of elements in the tree to n.
+// Inserting a new key into a full tree evicts the maximum or the minimum element according to policy.
+// If the new key itself would be evicted, Insert rejects it without allocating a node.
+// Evictions are reported to observers as deletions. Zero or negative n means no limit.
+func WithCapacity(n int, policy EvictPolicy) Option {
+	return func(o *Options) {
+		o.capacity = n
+		o.evictPolicy = policy
+	}
+}
+
+// WithEvictionCallback sets a function that is called for every element evicted
+// due to the capacity set by WithCapacity.
+// K and V must match the tree's type parameters.
+func WithEvictionCallback[K, V any](f func(k K, v V)) Option {
+	return func(o *Options) {
+		o.onEvict = f
+	}
+}
+
+func newEvictionCallback[K, V any](o any) func(k K, v V) {
+	if o == nil {
+		return nil
+	}
+	f, ok := o.(func(k K, v V))
+	if !ok {
+		panic(fmt.Sprintf("goavl: eviction callback type %T doesn't match the tree", o))
+	}
+	return f
+}
+
+// evictFor evicts an element to make room for k.
+// Returns false if k is the one that should be evicted.
+// Time complexity: O(logn).
+func (t *Tree[K, V, Cmp]) evictFor(k K) bool {
+	victim := t.max
+	if t.options.evictPolicy == EvictMin {
+		if t.cmp(k, t.min.key()) < 0 {
+			return false
+		}
+		victim = t.min
+	} else if t.cmp(k, t.max.key()) > 0 {
+		return false
+	}
+	vk, vv := t.deleteLocation(victim)
+	if t.onEvict != nil {
+		t.onEvict(vk, vv)
+	}
+	return true
+}
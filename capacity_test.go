@@ -0,0 +1,102 @@
+package goavl
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeCapacityEvictMax(t *testing.T) {
+	a := assert.New(t)
+	var evicted []int
+	tree := NewComparable[int, int](WithCountChildren(true), WithCapacity(3, EvictMax),
+		WithEvictionCallback(func(k, v int) {
+			evicted = append(evicted, k)
+		}))
+	for _, k := range []int{5, 1, 9} {
+		_, inserted := tree.Insert(k, k)
+		a.True(inserted)
+	}
+	ptr, inserted := tree.Insert(3, 3)
+	a.True(inserted)
+	a.Equal(3, *ptr)
+	a.Equal([]int{9}, evicted)
+
+	nextID := tree.nextID
+	ptr, inserted = tree.Insert(10, 10)
+	a.False(inserted)
+	a.Nil(ptr)
+	a.Equal(nextID, tree.nextID)
+
+	ptr, inserted = tree.Insert(5, 50)
+	a.False(inserted)
+	a.Equal(50, *ptr)
+	a.Equal([]int{9}, evicted)
+	assertTreeKeys(t, tree, []int{1, 3, 5})
+}
+
+func TestTreeCapacityEvictMin(t *testing.T) {
+	a := assert.New(t)
+	obs := &recordingObserver{}
+	tree := NewComparable[int, string](WithCapacity(2, EvictMin), WithObserver[int, string](obs))
+	tree.Insert(1, "a")
+	tree.Insert(2, "b")
+	_, inserted := tree.Insert(0, "z")
+	a.False(inserted)
+	tree.Insert(3, "c")
+	assertTreeKeys(t, tree, []int{2, 3})
+	a.Equal([]string{"insert 1=a", "insert 2=b", "delete 1=a", "insert 3=c"}, obs.events)
+}
+
+func TestTreeCapacityTopN(t *testing.T) {
+	const n = 16
+	a := assert.New(t)
+	r := rand.New(rand.NewSource(7))
+	tree := NewComparable[int, int](WithCountChildren(true), WithCapacity(n, EvictMin))
+	var all []int
+	for i := 0; i < 1000; i++ {
+		k := r.Intn(100000)
+		all = append(all, k)
+		tree.Insert(k, k)
+		a.LessOrEqual(tree.Len(), n)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(all)))
+	var want []int
+	for _, k := range all {
+		if len(want) > 0 && want[len(want)-1] == k {
+			continue
+		}
+		want = append(want, k)
+		if len(want) == n {
+			break
+		}
+	}
+	sort.Ints(want)
+	assertTreeKeys(t, tree, want)
+}
+
+func TestTreeCapacityRollbackAndGob(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int](WithCountChildren(true), WithCapacity(3, EvictMax))
+	for i := 0; i < 3; i++ {
+		tree.Insert(i*10, i)
+	}
+	tx := tree.Begin()
+	tx.Insert(5, 5)
+	tx.Insert(1, 1)
+	assertTreeKeys(t, tree, []int{0, 1, 5})
+	a.NoError(tx.Rollback())
+	assertTreeKeys(t, tree, []int{0, 10, 20})
+
+	big := NewComparable[int, int]()
+	for i := 0; i < 4; i++ {
+		big.Insert(i, i)
+	}
+	data, err := big.GobEncode()
+	a.NoError(err)
+	a.True(errors.Is(tree.GobDecode(data), ErrCapacityExceeded))
+	assertTreeKeys(t, tree, []int{0, 10, 20})
+}
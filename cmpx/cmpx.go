@@ -0,0 +1,131 @@
+// Package cmpx provides comparators and comparator combinators for goavl trees.
+//
+// All the functions return or are of type func(a, b T) int, so they can be passed to goavl.New directly:
+//
+//	type user struct {
+//		name string
+//		age  int
+//	}
+//	tree := goavl.New[user, int](cmpx.ThenBy(
+//		cmpx.Reverse(cmpx.By(func(u user) int { return u.age })),
+//		cmpx.By(func(u user) string { return u.name }),
+//	))
+package cmpx
+
+import (
+	"bytes"
+	"math"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Ordered compares values of ordered types.
+// For floats, use Float, as NaNs make Ordered inconsistent.
+func Ordered[T constraints.Ordered](a, b T) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// Float compares floats defining a total order: NaNs are equal to each other and less than any other value.
+// -0.0 and 0.0 are equal.
+func Float[T constraints.Float](a, b T) int {
+	aNaN, bNaN := math.IsNaN(float64(a)), math.IsNaN(float64(b))
+	switch {
+	case aNaN && bNaN:
+		return 0
+	case aNaN:
+		return -1
+	case bNaN:
+		return 1
+	}
+	return Ordered(a, b)
+}
+
+// Bytes compares byte slices lexicographically.
+func Bytes(a, b []byte) int {
+	return bytes.Compare(a, b)
+}
+
+// Time compares time instants. Monotonic clock readings are used if both times have them.
+func Time(a, b time.Time) int {
+	return a.Compare(b)
+}
+
+// CaseInsensitive compares utf-8 strings rune by rune, ignoring the case.
+// Strings are equal if strings.EqualFold returns true for them. It doesn't allocate.
+func CaseInsensitive(a, b string) int {
+	for a != "" && b != "" {
+		ra, na := utf8.DecodeRuneInString(a)
+		rb, nb := utf8.DecodeRuneInString(b)
+		if ra != rb {
+			if c := Ordered(foldRune(ra), foldRune(rb)); c != 0 {
+				return c
+			}
+		}
+		a, b = a[na:], b[nb:]
+	}
+	return Ordered(len(a), len(b))
+}
+
+// foldRune maps all the runes considered equal by strings.EqualFold to the same rune:
+// the smallest rune of the case folding orbit of r, or a lowercase letter for ASCII.
+func foldRune(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'A' <= r && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		return r
+	}
+	smallest := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < smallest {
+			smallest = f
+		}
+	}
+	if 'A' <= smallest && smallest <= 'Z' {
+		smallest += 'a' - 'A'
+	}
+	return smallest
+}
+
+// Reverse returns a comparator for the reversed order.
+func Reverse[T any](cmp func(a, b T) int) func(a, b T) int {
+	return func(a, b T) int {
+		return cmp(b, a)
+	}
+}
+
+// By returns a comparator comparing the keys extracted from the values.
+func By[T any, K constraints.Ordered](key func(v T) K) func(a, b T) int {
+	return func(a, b T) int {
+		return Ordered(key(a), key(b))
+	}
+}
+
+// ByFunc returns a comparator comparing the keys extracted from the values with cmp.
+func ByFunc[T, K any](key func(v T) K, cmp func(a, b K) int) func(a, b T) int {
+	return func(a, b T) int {
+		return cmp(key(a), key(b))
+	}
+}
+
+// ThenBy returns a lexicographical comparator for composite keys:
+// the values are compared with the next comparator only if all the previous ones returned 0.
+func ThenBy[T any](cmps ...func(a, b T) int) func(a, b T) int {
+	return func(a, b T) int {
+		for _, cmp := range cmps {
+			if c := cmp(a, b); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+}
@@ -0,0 +1,90 @@
+package cmpx
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/avdva/goavl"
+)
+
+type user struct {
+	age  int
+	name string
+}
+
+func userCmp(a, b user) int {
+	if a.age != b.age {
+		if a.age < b.age {
+			return -1
+		}
+		return 1
+	}
+	if a.name < b.name {
+		return -1
+	}
+	if a.name > b.name {
+		return 1
+	}
+	return 0
+}
+
+func randomUsers(n int) []user {
+	r := rand.New(rand.NewSource(1))
+	users := make([]user, n)
+	for i := range users {
+		users[i] = user{age: r.Intn(100), name: strconv.Itoa(r.Int())}
+	}
+	return users
+}
+
+func benchmarkTreeInsert[K any](b *testing.B, keys []K, cmp func(a, b K) int) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tree := goavl.New[K, int](cmp)
+		for j, k := range keys {
+			tree.Insert(k, j)
+		}
+	}
+}
+
+func BenchmarkInt_HandWritten(b *testing.B) {
+	keys := rand.New(rand.NewSource(1)).Perm(10000)
+	benchmarkTreeInsert(b, keys, func(a, b int) int {
+		if a < b {
+			return -1
+		}
+		if a > b {
+			return 1
+		}
+		return 0
+	})
+}
+
+func BenchmarkInt_Ordered(b *testing.B) {
+	benchmarkTreeInsert(b, rand.New(rand.NewSource(1)).Perm(10000), Ordered[int])
+}
+
+func BenchmarkInt_Reverse(b *testing.B) {
+	benchmarkTreeInsert(b, rand.New(rand.NewSource(1)).Perm(10000), Reverse(Ordered[int]))
+}
+
+func BenchmarkComposite_HandWritten(b *testing.B) {
+	benchmarkTreeInsert(b, randomUsers(10000), userCmp)
+}
+
+func BenchmarkComposite_ThenBy(b *testing.B) {
+	benchmarkTreeInsert(b, randomUsers(10000), ThenBy(
+		By(func(u user) int { return u.age }),
+		By(func(u user) string { return u.name }),
+	))
+}
+
+func BenchmarkString_CaseInsensitive(b *testing.B) {
+	users := randomUsers(10000)
+	keys := make([]string, len(users))
+	for i, u := range users {
+		keys[i] = "User-" + u.name
+	}
+	benchmarkTreeInsert(b, keys, CaseInsensitive)
+}
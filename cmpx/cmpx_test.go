@@ -0,0 +1,80 @@
+package cmpx
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avdva/goavl"
+	"github.com/stretchr/testify/assert"
+)
+
+type pair struct {
+	a int
+	b string
+}
+
+func TestComparators(t *testing.T) {
+	a := assert.New(t)
+	a.NoError(goavl.CheckComparator(Ordered[int], []int{1, -1, 0, 5, 5}))
+	a.Equal(-1, Ordered("a", "b"))
+
+	floats := []float64{math.NaN(), 1, math.Inf(1), math.Inf(-1), math.Copysign(0, -1), 0, math.NaN()}
+	a.NoError(goavl.CheckComparator(Float[float64], floats))
+	a.Error(goavl.CheckComparator(Ordered[float64], floats))
+	a.Equal(-1, Float(math.NaN(), math.Inf(-1)))
+	a.Equal(0, Float(float32(math.NaN()), float32(math.NaN())))
+
+	a.NoError(goavl.CheckComparator(Bytes, [][]byte{nil, {}, {1}, {1, 2}, {2}}))
+	a.Equal(1, Bytes([]byte{2}, []byte{1, 2}))
+
+	now := time.Now()
+	times := []time.Time{now, now.Add(time.Second), now.Round(0), now.UTC(), {}}
+	a.NoError(goavl.CheckComparator(Time, times))
+	a.Equal(0, Time(now, now.UTC()))
+	a.Equal(-1, Time(time.Time{}, now))
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	a := assert.New(t)
+	samples := []string{"", "a", "A", "b", "_", "a_", "ab", "AB", "K", "K", "k", "ſ", "S", "straße", "STRASSE", "Σ", "ς", "σ", "\xff"}
+	a.NoError(goavl.CheckComparator(CaseInsensitive, samples))
+	for _, x := range samples {
+		for _, y := range samples {
+			a.Equalf(strings.EqualFold(x, y), CaseInsensitive(x, y) == 0, "%q, %q", x, y)
+		}
+	}
+	a.Equal(-1, CaseInsensitive("a_", "AB"))
+	a.Equal(-1, CaseInsensitive("abc", "ABCD"))
+	a.Equal(1, CaseInsensitive("b", "A"))
+}
+
+func TestCombinators(t *testing.T) {
+	a := assert.New(t)
+	rev := Reverse(Ordered[int])
+	a.Equal(1, rev(1, 2))
+	a.NoError(goavl.CheckComparator(rev, []int{3, 1, 2}))
+
+	cmp := ThenBy(
+		Reverse(By(func(p pair) int { return p.a })),
+		ByFunc(func(p pair) string { return p.b }, CaseInsensitive),
+	)
+	samples := []pair{{1, "a"}, {1, "B"}, {2, "a"}, {2, "A"}, {0, "z"}}
+	a.NoError(goavl.CheckComparator(cmp, samples))
+
+	tree := goavl.New[pair, int](cmp)
+	for i, p := range samples {
+		tree.Insert(p, i)
+	}
+	var keys []pair
+	for it := tree.IteratorAtFirst(); ; {
+		e, ok := it.Next()
+		if !ok {
+			break
+		}
+		keys = append(keys, e.Key)
+	}
+	a.Equal([]pair{{2, "a"}, {1, "a"}, {1, "B"}, {0, "z"}}, keys)
+	a.Equal(0, ThenBy[int]()(1, 2))
+}
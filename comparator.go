@@ -0,0 +1,102 @@
+package goavl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInconsistentComparator is wrapped by the errors returned from CheckComparator,
+// and by the panics caused by WithComparatorChecks.
+var ErrInconsistentComparator = errors.New("goavl: inconsistent comparator")
+
+// WithComparatorChecks makes the tree verify every answer of the comparator:
+// cmp(a, a) and cmp(b, b) must be 0, and cmp(b, a) must have the opposite sign of cmp(a, b).
+// On a violation the tree panics with an error wrapping ErrInconsistentComparator,
+// that contains the offending keys.
+// Every comparison calls cmp four times instead of once, so it's only intended for debugging.
+func WithComparatorChecks(enabled bool) Option {
+	return func(o *Options) {
+		o.checkCmp = enabled
+	}
+}
+
+// CheckComparator checks that cmp defines a strict weak order on the samples:
+//   - reflexivity: cmp(a, a) == 0;
+//   - antisymmetry: cmp(a, b) and cmp(b, a) have opposite signs;
+//   - transitivity: a <= b and b <= c imply a <= c, where the result is 0 only if both comparisons are 0.
+//
+// The samples can be generated or taken from real data, they should include edge cases, like NaNs for floats.
+// Returns an error wrapping ErrInconsistentComparator and describing the first violation.
+// Time complexity: O(n^3).
+func CheckComparator[K any](cmp func(a, b K) int, samples []K) error {
+	for _, a := range samples {
+		if c := cmp(a, a); c != 0 {
+			return fmt.Errorf("%w: not reflexive: cmp(%v, %v) = %d", ErrInconsistentComparator, a, a, c)
+		}
+	}
+	for i, a := range samples {
+		for _, b := range samples[i+1:] {
+			if err := checkAntisymmetry(cmp, a, b, cmp(a, b)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, a := range samples {
+		for _, b := range samples {
+			ab := sign(cmp(a, b))
+			if ab > 0 {
+				continue
+			}
+			for _, c := range samples {
+				bc := sign(cmp(b, c))
+				if bc > 0 {
+					continue
+				}
+				want := 0
+				if ab < 0 || bc < 0 {
+					want = -1
+				}
+				if ac := sign(cmp(a, c)); ac != want {
+					return fmt.Errorf("%w: not transitive: cmp(%v, %v) = %d, cmp(%v, %v) = %d, but cmp(%v, %v) = %d",
+						ErrInconsistentComparator, a, b, ab, b, c, bc, a, c, ac)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkAntisymmetry checks cmp(b, a) against ab, the result of cmp(a, b).
+func checkAntisymmetry[K any](cmp func(a, b K) int, a, b K, ab int) error {
+	if ba := cmp(b, a); sign(ab) != -sign(ba) {
+		return fmt.Errorf("%w: not antisymmetric: cmp(%v, %v) = %d, cmp(%v, %v) = %d",
+			ErrInconsistentComparator, a, b, ab, b, a, ba)
+	}
+	return nil
+}
+
+// checkedComparator wraps cmp to panic on inconsistent answers.
+func checkedComparator[K any, Cmp func(a, b K) int](cmp Cmp) Cmp {
+	return func(a, b K) int {
+		for _, k := range [...]K{a, b} {
+			if c := cmp(k, k); c != 0 {
+				panic(fmt.Errorf("%w: not reflexive: cmp(%v, %v) = %d", ErrInconsistentComparator, k, k, c))
+			}
+		}
+		ab := cmp(a, b)
+		if err := checkAntisymmetry(cmp, a, b, ab); err != nil {
+			panic(err)
+		}
+		return ab
+	}
+}
+
+func sign(c int) int {
+	switch {
+	case c < 0:
+		return -1
+	case c > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,78 @@
+package goavl
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckComparator(t *testing.T) {
+	a := assert.New(t)
+	a.NoError(CheckComparator(intCmp, []int{3, 1, 2, 2, -5}))
+	a.NoError(CheckComparator(intCmp, nil))
+
+	floatCmp := NewComparable[float64, int]().cmp
+	a.NoError(CheckComparator(floatCmp, []float64{1, 2, math.Inf(-1)}))
+	err := CheckComparator(floatCmp, []float64{1, math.NaN(), 2})
+	a.True(errors.Is(err, ErrInconsistentComparator))
+	a.ErrorContains(err, "not transitive")
+
+	err = CheckComparator(func(a, b int) int { return 1 }, []int{1})
+	a.ErrorContains(err, "not reflexive: cmp(1, 1) = 1")
+
+	err = CheckComparator(func(a, b int) int {
+		if a == b {
+			return 0
+		}
+		return -1
+	}, []int{1, 2})
+	a.ErrorContains(err, "not antisymmetric: cmp(1, 2) = -1, cmp(2, 1) = -1")
+
+	// rock-paper-scissors.
+	err = CheckComparator(func(a, b int) int {
+		switch {
+		case a == b:
+			return 0
+		case (a+1)%3 == b:
+			return -1
+		}
+		return 1
+	}, []int{0, 1, 2})
+	a.ErrorContains(err, "not transitive")
+}
+
+func TestTreeComparatorChecks(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int](WithComparatorChecks(true))
+	for i := 0; i < 100; i++ {
+		tree.Insert(i, i)
+	}
+	a.Equal(100, tree.Len())
+
+	var broken bool
+	bad := New[int, int](func(a, b int) int {
+		if broken && a != b {
+			return 1
+		}
+		return intCmp(a, b)
+	}, WithComparatorChecks(true))
+	bad.Insert(1, 1)
+	bad.Insert(60, 60)
+	broken = true
+	a.PanicsWithError(`goavl: inconsistent comparator: not antisymmetric: cmp(55, 1) = 1, cmp(1, 55) = 1`, func() {
+		bad.Insert(55, 55)
+	})
+}
+
+func TestCheckedComparatorCalls(t *testing.T) {
+	a := assert.New(t)
+	var calls int
+	cmp := checkedComparator(func(a, b int) int {
+		calls++
+		return intCmp(a, b)
+	})
+	a.Equal(-1, cmp(1, 2))
+	a.Equal(4, calls)
+}
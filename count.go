@@ -0,0 +1,9 @@
+//go:build !goavl_widecount
+
+package goavl
+
+// childCount is the type of the children counts stored in every node, if WithCountChildren is enabled.
+// Build with the goavl_widecount tag to use uint64 counts for trees with more than 2^32-1 elements.
+type childCount = uint32
+
+const maxChildCount = 1<<32 - 1
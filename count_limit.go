@@ -0,0 +1,27 @@
+package goavl
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrCountOverflow is the panic value of Insert and the error returned by bulk loads,
+// if a tree with WithCountChildren would have more elements than its children counts can hold.
+// Build with the goavl_widecount tag to raise the limit from 2^32-1 to 2^63-1 elements.
+var ErrCountOverflow = errors.New("goavl: too many elements for children counts")
+
+// maxCountedLen is the maximum length of a tree with children counts.
+// Both the counts and the positions, which are ints, must not overflow.
+var maxCountedLen = countedLenLimit(maxChildCount)
+
+func countedLenLimit(maxCount uint64) int {
+	if maxCount > math.MaxInt {
+		return math.MaxInt
+	}
+	return int(maxCount)
+}
+
+// canAddCounted returns false if the children counts would overflow after adding n elements.
+func (t *Tree[K, V, Cmp]) canAddCounted(n int) bool {
+	return !t.options.countChildren || n <= maxCountedLen-t.length
+}
@@ -0,0 +1,84 @@
+package goavl
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountedLenLimit(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(math.MaxInt, countedLenLimit(math.MaxUint64))
+	a.Equal(1000, countedLenLimit(1000))
+	a.LessOrEqual(uint64(maxCountedLen), uint64(maxChildCount))
+}
+
+func TestTreeCountOverflow(t *testing.T) {
+	a := assert.New(t)
+	defer func(limit int) {
+		maxCountedLen = limit
+	}(maxCountedLen)
+	maxCountedLen = 3
+
+	tree := NewComparable[int, int](WithCountChildren(true))
+	for i := 0; i < 3; i++ {
+		tree.Insert(i, i)
+	}
+	_, inserted := tree.Insert(1, 10)
+	a.False(inserted)
+	a.PanicsWithError(ErrCountOverflow.Error(), func() {
+		tree.Insert(3, 3)
+	})
+	a.NoError(tree.Validate())
+	tree.Delete(0)
+	_, inserted = tree.Insert(3, 3)
+	a.True(inserted)
+
+	big := NewComparable[int, int]()
+	for i := 0; i < 4; i++ {
+		big.Insert(i, i)
+	}
+	data, err := big.GobEncode()
+	a.NoError(err)
+	a.True(errors.Is(tree.GobDecode(data), ErrCountOverflow))
+	assertTreeKeys(t, tree, []int{1, 2, 3})
+
+	uncounted := NewComparable[int, int]()
+	a.NoError(uncounted.GobDecode(data))
+	uncounted.Insert(4, 4)
+	a.Equal(5, uncounted.Len())
+}
+
+// TestTreeLargeCounts fakes the children counts of a small tree to check
+// that positions beyond 2^31 are computed without wraparound.
+func TestTreeLargeCounts(t *testing.T) {
+	if math.MaxInt == math.MaxInt32 {
+		t.Skip("positions beyond 2^31 require 64-bit ints")
+	}
+	a := assert.New(t)
+	tree := NewComparable[int, int](WithCountChildren(true))
+	for i := 1; i <= 3; i++ {
+		tree.Insert(i, i)
+	}
+	// pretend the left subtree contains `fake` nodes.
+	fake := childCount(maxCountedLen/2 + 7)
+	left := tree.root.left()
+	left.setChildrenCount(fake - 1)
+	tree.length = int(fake) + 2
+
+	rank, found := tree.Rank(2)
+	a.True(found)
+	a.Equal(int(fake), rank)
+	rank, found = tree.Rank(3)
+	a.True(found)
+	a.Equal(int(fake)+1, rank)
+	a.Equal(int(fake)+1, tree.CountInRange(0, 2))
+	a.Equal(2, tree.CountInRange(2, 3))
+	a.Equal(2, tree.At(int(fake)).Key)
+	distance, _ := tree.RankDistance(1, 3)
+	a.Equal(int(fake)+1, distance)
+	tree.root.recalcCounts()
+	a.Equal(fake+1, tree.root.childrenCount())
+}
@@ -0,0 +1,9 @@
+//go:build goavl_widecount
+
+package goavl
+
+// childCount is the type of the children counts stored in every node, if WithCountChildren is enabled.
+// The goavl_widecount build tag makes nodes 8 bytes larger on 64-bit platforms.
+type childCount = uint64
+
+const maxChildCount = 1<<64 - 1
@@ -0,0 +1,209 @@
+package goavl
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithAfter sets the function used by time-aware types to wait for a duration.
+// Together with WithClock it allows to control time in tests.
+// By default a time.Timer is used, which is stopped as soon as the wait is over.
+func WithAfter(after func(d time.Duration) <-chan time.Time) Option {
+	return func(o *Options) {
+		o.after = after
+	}
+}
+
+type delayKey struct {
+	at  time.Time
+	seq uint64
+}
+
+type delayItem[K comparable, V any] struct {
+	k K
+	v V
+}
+
+func compareDelayKeys(a, b delayKey) int {
+	if c := a.at.Compare(b.at); c != 0 {
+		return c
+	}
+	switch {
+	case a.seq < b.seq:
+		return -1
+	case a.seq > b.seq:
+		return 1
+	}
+	return 0
+}
+
+// DelayQueue is a queue of items ordered by their deadlines.
+// Items are identified by unique keys, which allows to reschedule or cancel them.
+// Items with equal deadlines are taken in the order they were scheduled.
+// DelayQueue is safe for concurrent use.
+type DelayQueue[K comparable, V any] struct {
+	mu    sync.Mutex
+	items *Tree[delayKey, delayItem[K, V], func(a, b delayKey) int]
+	index map[K]delayKey
+	seq   uint64
+	now   func() time.Time
+	// newTimer returns a channel receiving the time after d and a function releasing the timer.
+	newTimer func(d time.Duration) (<-chan time.Time, func() bool)
+	// wake is closed and replaced every time the earliest deadline may have changed.
+	wake chan struct{}
+}
+
+// NewDelayQueue returns a new DelayQueue.
+// WithClock and WithAfter options are supported.
+func NewDelayQueue[K comparable, V any](opts ...Option) *DelayQueue[K, V] {
+	options := newOptions(opts)
+	q := &DelayQueue[K, V]{
+		items:    New[delayKey, delayItem[K, V]](compareDelayKeys),
+		index:    make(map[K]delayKey),
+		now:      options.clock,
+		newTimer: newStoppableTimer,
+		wake:     make(chan struct{}),
+	}
+	if q.now == nil {
+		q.now = time.Now
+	}
+	if after := options.after; after != nil {
+		q.newTimer = func(d time.Duration) (<-chan time.Time, func() bool) {
+			return after(d), func() bool { return false }
+		}
+	}
+	return q
+}
+
+func newStoppableTimer(d time.Duration) (<-chan time.Time, func() bool) {
+	t := time.NewTimer(d)
+	return t.C, t.Stop
+}
+
+// Schedule adds an item with key k that becomes available at the given time.
+// If k is already in the queue, its value and deadline are replaced.
+// Time complexity: O(logn).
+func (q *DelayQueue[K, V]) Schedule(k K, v V, at time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if dk, found := q.index[k]; found {
+		q.items.Delete(dk)
+	}
+	q.insert(k, v, at)
+}
+
+// Reschedule changes the deadline of the item with key k.
+// Returns false if k is not in the queue.
+// Time complexity: O(logn).
+func (q *DelayQueue[K, V]) Reschedule(k K, at time.Time) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	dk, found := q.index[k]
+	if !found {
+		return false
+	}
+	item, _ := q.items.Delete(dk)
+	q.insert(k, item.v, at)
+	return true
+}
+
+// Cancel removes the item with key k from the queue.
+// Returns the value and true, if k was in the queue.
+// Time complexity: O(logn).
+func (q *DelayQueue[K, V]) Cancel(k K) (v V, canceled bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	dk, found := q.index[k]
+	if !found {
+		return v, false
+	}
+	delete(q.index, k)
+	item, _ := q.items.Delete(dk)
+	q.notify()
+	return item.v, true
+}
+
+// Peek returns the item with the earliest deadline without removing it.
+// Time complexity: O(1).
+func (q *DelayQueue[K, V]) Peek() (k K, v V, at time.Time, found bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	e, found := q.items.Min()
+	if !found {
+		return k, v, at, false
+	}
+	return e.Value.k, e.Value.v, e.Key.at, true
+}
+
+// Len returns the number of items in the queue, including the ones not available yet.
+func (q *DelayQueue[K, V]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.items.Len()
+}
+
+// Poll removes and returns the item with the earliest deadline, if the deadline has passed.
+// Time complexity: O(logn).
+func (q *DelayQueue[K, V]) Poll() (k K, v V, found bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	k, v, found, _ = q.pollLocked()
+	return k, v, found
+}
+
+// Take removes and returns the item with the earliest deadline,
+// waiting until the deadline has passed, or ctx is done.
+// Returns ctx.Err() if ctx is done first.
+func (q *DelayQueue[K, V]) Take(ctx context.Context) (k K, v V, err error) {
+	for {
+		q.mu.Lock()
+		k, v, found, wait := q.pollLocked()
+		wake := q.wake
+		q.mu.Unlock()
+		if found {
+			return k, v, nil
+		}
+		var timer <-chan time.Time
+		stop := func() bool { return false }
+		if wait > 0 {
+			timer, stop = q.newTimer(wait)
+		}
+		select {
+		case <-ctx.Done():
+			stop()
+			return k, v, ctx.Err()
+		case <-wake:
+		case <-timer:
+		}
+		stop()
+	}
+}
+
+// pollLocked pops the earliest item if it's available.
+// Otherwise returns the time to wait for it, or zero if the queue is empty.
+func (q *DelayQueue[K, V]) pollLocked() (k K, v V, found bool, wait time.Duration) {
+	e, ok := q.items.Min()
+	if !ok {
+		return k, v, false, 0
+	}
+	if wait = e.Key.at.Sub(q.now()); wait > 0 {
+		return k, v, false, wait
+	}
+	_, item, _ := q.items.PopMin()
+	delete(q.index, item.k)
+	return item.k, item.v, true, 0
+}
+
+func (q *DelayQueue[K, V]) insert(k K, v V, at time.Time) {
+	q.seq++
+	dk := delayKey{at: at, seq: q.seq}
+	q.items.Insert(dk, delayItem[K, V]{k: k, v: v})
+	q.index[k] = dk
+	q.notify()
+}
+
+func (q *DelayQueue[K, V]) notify() {
+	close(q.wake)
+	q.wake = make(chan struct{})
+}
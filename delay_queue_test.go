@@ -0,0 +1,186 @@
+package goavl
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreePopMinMax(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int](WithCountChildren(true))
+	_, _, found := tree.PopMin()
+	a.False(found)
+	_, _, found = tree.PopMax()
+	a.False(found)
+	for _, k := range []int{5, 3, 8, 1, 9, 2} {
+		tree.Insert(k, k*10)
+	}
+	k, v, found := tree.PopMin()
+	a.True(found)
+	a.Equal(1, k)
+	a.Equal(10, v)
+	k, v, found = tree.PopMax()
+	a.True(found)
+	a.Equal(9, k)
+	a.Equal(90, v)
+	assertTreeKeys(t, tree, []int{2, 3, 5, 8})
+	for tree.Len() > 0 {
+		tree.PopMin()
+		a.NoError(checkHeightAndBalance(tree.root, true))
+	}
+}
+
+type fakeTimer struct {
+	requests chan time.Duration
+	fire     chan time.Time
+}
+
+func newFakeTimer() *fakeTimer {
+	return &fakeTimer{requests: make(chan time.Duration, 16), fire: make(chan time.Time)}
+}
+
+func (ft *fakeTimer) After(d time.Duration) <-chan time.Time {
+	ft.requests <- d
+	return ft.fire
+}
+
+func TestDelayQueue(t *testing.T) {
+	a := assert.New(t)
+	clock := newFakeClock()
+	start := clock.Now()
+	q := NewDelayQueue[string, int](WithClock(clock.Now))
+	q.Schedule("c", 3, start.Add(3*time.Second))
+	q.Schedule("a", 1, start.Add(time.Second))
+	q.Schedule("b", 2, start.Add(2*time.Second))
+	q.Schedule("b2", 22, start.Add(2*time.Second))
+	a.Equal(4, q.Len())
+
+	k, v, at, found := q.Peek()
+	a.True(found)
+	a.Equal("a", k)
+	a.Equal(1, v)
+	a.Equal(start.Add(time.Second), at)
+
+	_, _, found = q.Poll()
+	a.False(found)
+
+	a.True(q.Reschedule("c", start))
+	a.False(q.Reschedule("x", start))
+	v, canceled := q.Cancel("a")
+	a.True(canceled)
+	a.Equal(1, v)
+	_, canceled = q.Cancel("a")
+	a.False(canceled)
+
+	k, v, found = q.Poll()
+	a.True(found)
+	a.Equal("c", k)
+	a.Equal(3, v)
+
+	clock.Advance(2 * time.Second)
+	var keys []string
+	for {
+		k, _, found := q.Poll()
+		if !found {
+			break
+		}
+		keys = append(keys, k)
+	}
+	a.Equal([]string{"b", "b2"}, keys)
+	a.Zero(q.Len())
+	a.Empty(q.index)
+
+	q.Schedule("d", 4, clock.Now())
+	q.Schedule("d", 5, clock.Now().Add(time.Hour))
+	a.Equal(1, q.Len())
+	_, v, _, _ = q.Peek()
+	a.Equal(5, v)
+}
+
+func TestDelayQueueTake(t *testing.T) {
+	a := assert.New(t)
+	clock := newFakeClock()
+	timer := newFakeTimer()
+	q := NewDelayQueue[int, string](WithClock(clock.Now), WithAfter(timer.After))
+
+	type result struct {
+		k   int
+		v   string
+		err error
+	}
+	take := func(ctx context.Context) chan result {
+		ch := make(chan result, 1)
+		go func() {
+			k, v, err := q.Take(ctx)
+			ch <- result{k: k, v: v, err: err}
+		}()
+		return ch
+	}
+
+	q.Schedule(1, "a", clock.Now().Add(10*time.Second))
+	res := take(context.Background())
+	a.Equal(10*time.Second, <-timer.requests)
+	clock.Advance(10 * time.Second)
+	timer.fire <- clock.Now()
+	a.Equal(result{k: 1, v: "a"}, <-res)
+
+	// an empty queue waits for a new item without a timer.
+	res = take(context.Background())
+	q.Schedule(2, "b", clock.Now().Add(5*time.Second))
+	a.Equal(5*time.Second, <-timer.requests)
+	// rescheduling wakes the waiter up.
+	q.Reschedule(2, clock.Now())
+	a.Equal(result{k: 2, v: "b"}, <-res)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	q.Schedule(3, "c", clock.Now().Add(time.Hour))
+	res = take(ctx)
+	a.Equal(time.Hour, <-timer.requests)
+	cancel()
+	r := <-res
+	a.ErrorIs(r.err, context.Canceled)
+	a.Equal(1, q.Len())
+}
+
+func TestDelayQueueTakeStopsTimers(t *testing.T) {
+	a := assert.New(t)
+	q := NewDelayQueue[int, string]()
+	var live, created atomic.Int64
+	q.newTimer = func(d time.Duration) (<-chan time.Time, func() bool) {
+		created.Add(1)
+		live.Add(1)
+		ch, stop := newStoppableTimer(d)
+		var once sync.Once
+		return ch, func() bool {
+			once.Do(func() { live.Add(-1) })
+			return stop()
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	res := make(chan error, 1)
+	go func() {
+		_, _, err := q.Take(ctx)
+		res <- err
+	}()
+	far := time.Now().Add(time.Hour)
+	for i := 0; i < 100; i++ {
+		q.Schedule(i, "far", far)
+		time.Sleep(100 * time.Microsecond)
+	}
+	cancel()
+	a.ErrorIs(<-res, context.Canceled)
+	a.NotZero(created.Load())
+	a.Zero(live.Load())
+
+	q.Schedule(-1, "soon", time.Now().Add(10*time.Millisecond))
+	k, v, err := q.Take(context.Background())
+	a.NoError(err)
+	a.Equal(-1, k)
+	a.Equal("soon", v)
+	a.Zero(live.Load())
+}
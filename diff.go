@@ -0,0 +1,80 @@
+package goavl
+
+// DiffKind is the type of a difference between two trees.
+type DiffKind int8
+
+const (
+	// DiffAdded means that the key is present only in the second tree.
+	DiffAdded DiffKind = iota + 1
+	// DiffRemoved means that the key is present only in the first tree.
+	DiffRemoved
+	// DiffChanged means that the key is present in both trees with different values.
+	DiffChanged
+)
+
+// DiffEntry describes a difference between two trees.
+type DiffEntry[K, V any] struct {
+	Kind DiffKind
+	Key  K
+	// Old points to the value in the first tree. It's nil for DiffAdded.
+	Old *V
+	// New points to the value in the second tree. It's nil for DiffRemoved.
+	New *V
+}
+
+// DiffEach calls f for every difference between trees a and b in ascending key order, until f returns false.
+// Keys are compared with a's comparator, both trees must be ordered the same way.
+// Values of the keys present in both trees are compared with eq.
+// Tree nodes are never shared between trees, so the only shortcut is a == b, which has no differences.
+// Time complexity: O(n+m).
+func DiffEach[K, V any, Cmp func(a, b K) int](a, b *Tree[K, V, Cmp], eq func(x, y *V) bool, f func(DiffEntry[K, V]) bool) {
+	if a == b {
+		return
+	}
+	ait, bit := a.IteratorAtFirst(), b.IteratorAtFirst()
+	ae, aok := ait.Next()
+	be, bok := bit.Next()
+	for aok || bok {
+		var c int
+		switch {
+		case !bok:
+			c = -1
+		case !aok:
+			c = 1
+		default:
+			c = a.cmp(ae.Key, be.Key)
+		}
+		var d DiffEntry[K, V]
+		switch {
+		case c < 0:
+			d = DiffEntry[K, V]{Kind: DiffRemoved, Key: ae.Key, Old: ae.Value}
+			ae, aok = ait.Next()
+		case c > 0:
+			d = DiffEntry[K, V]{Kind: DiffAdded, Key: be.Key, New: be.Value}
+			be, bok = bit.Next()
+		default:
+			if !eq(ae.Value, be.Value) {
+				d = DiffEntry[K, V]{Kind: DiffChanged, Key: be.Key, Old: ae.Value, New: be.Value}
+			}
+			ae, aok = ait.Next()
+			be, bok = bit.Next()
+		}
+		if d.Kind != 0 && !f(d) {
+			return
+		}
+	}
+}
+
+// Equal returns true if trees a and b have the same keys, and eq returns true for their values.
+// Time complexity: O(n), O(1) if the lengths differ.
+func Equal[K, V any, Cmp func(a, b K) int](a, b *Tree[K, V, Cmp], eq func(x, y *V) bool) bool {
+	if a.Len() != b.Len() {
+		return false
+	}
+	equal := true
+	DiffEach(a, b, eq, func(DiffEntry[K, V]) bool {
+		equal = false
+		return false
+	})
+	return equal
+}
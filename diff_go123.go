@@ -0,0 +1,13 @@
+//go:build go1.23
+
+package goavl
+
+import "iter"
+
+// Diff returns an iterator over the differences between trees a and b in ascending key order.
+// It can be used in a for-range loop (Go 1.23+). See DiffEach for the details.
+func Diff[K, V any, Cmp func(a, b K) int](a, b *Tree[K, V, Cmp], eq func(x, y *V) bool) iter.Seq[DiffEntry[K, V]] {
+	return func(yield func(DiffEntry[K, V]) bool) {
+		DiffEach(a, b, eq, yield)
+	}
+}
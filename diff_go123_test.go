@@ -0,0 +1,24 @@
+//go:build go1.23
+
+package goavl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffGo123(t *testing.T) {
+	a := assert.New(t)
+	t1 := NewComparable[int, int]()
+	t2 := NewComparable[int, int]()
+	t1.Insert(1, 1)
+	t1.Insert(2, 2)
+	t2.Insert(2, 20)
+	t2.Insert(3, 3)
+	var kinds []DiffKind
+	for d := range Diff(t1, t2, intPtrEq) {
+		kinds = append(kinds, d.Kind)
+	}
+	a.Equal([]DiffKind{DiffRemoved, DiffChanged, DiffAdded}, kinds)
+}
@@ -0,0 +1,72 @@
+package goavl
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtrEq(x, y *int) bool {
+	return *x == *y
+}
+
+func collectDiff(a, b *Tree[int, int, func(a, b int) int]) []string {
+	var result []string
+	DiffEach(a, b, intPtrEq, func(d DiffEntry[int, int]) bool {
+		switch d.Kind {
+		case DiffAdded:
+			result = append(result, "+"+strconv.Itoa(d.Key)+"="+strconv.Itoa(*d.New))
+		case DiffRemoved:
+			result = append(result, "-"+strconv.Itoa(d.Key)+"="+strconv.Itoa(*d.Old))
+		case DiffChanged:
+			result = append(result, "~"+strconv.Itoa(d.Key)+"="+strconv.Itoa(*d.Old)+"->"+strconv.Itoa(*d.New))
+		}
+		return true
+	})
+	return result
+}
+
+func TestDiff(t *testing.T) {
+	a := assert.New(t)
+	t1 := NewComparable[int, int]()
+	t2 := NewComparable[int, int]()
+	for _, k := range []int{1, 2, 3, 5, 8} {
+		t1.Insert(k, k)
+	}
+	for _, k := range []int{0, 2, 3, 5, 9, 10} {
+		t2.Insert(k, k)
+	}
+	t2.Insert(3, 30)
+	a.Equal([]string{"+0=0", "-1=1", "~3=3->30", "-8=8", "+9=9", "+10=10"}, collectDiff(t1, t2))
+	a.Equal([]string{"-0=0", "+1=1", "~3=30->3", "+8=8", "-9=9", "-10=10"}, collectDiff(t2, t1))
+	a.Empty(collectDiff(t1, t1))
+	a.Equal([]string{"-1=1", "-2=2", "-3=3", "-5=5", "-8=8"}, collectDiff(t1, NewComparable[int, int]()))
+
+	var count int
+	DiffEach(t1, t2, intPtrEq, func(DiffEntry[int, int]) bool {
+		count++
+		return count < 2
+	})
+	a.Equal(2, count)
+}
+
+func TestEqual(t *testing.T) {
+	a := assert.New(t)
+	t1 := NewComparable[int, int]()
+	t2 := NewComparable[int, int](WithCountChildren(true))
+	a.True(Equal(t1, t2, intPtrEq))
+	for i := 0; i < 100; i++ {
+		t1.Insert(i, i)
+		t2.Insert(99-i, 99-i)
+	}
+	a.True(Equal(t1, t2, intPtrEq))
+	a.True(Equal(t1, t1, intPtrEq))
+	t2.Insert(50, -1)
+	a.False(Equal(t1, t2, intPtrEq))
+	t2.Insert(50, 50)
+	t2.UpdateKey(0, 100)
+	a.False(Equal(t1, t2, intPtrEq))
+	t2.Delete(100)
+	a.False(Equal(t1, t2, intPtrEq))
+}
@@ -0,0 +1,145 @@
+package goavl
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DumpOptions configures WriteDOT and WriteASCII.
+type DumpOptions[K any] struct {
+	// MaxDepth, if positive, limits the output to the nodes with depth < MaxDepth.
+	// The root has depth 0. Truncated subtrees are shown as "...".
+	MaxDepth int
+	// Path, if set, highlights the nodes visited while searching for the key.
+	Path *K
+	// HideValues removes values from node labels.
+	HideValues bool
+}
+
+// dumpWriter remembers the first write error.
+type dumpWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (dw *dumpWriter) printf(format string, args ...any) {
+	if dw.err == nil {
+		_, dw.err = fmt.Fprintf(dw.w, format, args...)
+	}
+}
+
+// onPath returns the set of nodes visited while searching for opts.Path.
+func (t *Tree[K, V, Cmp]) onPath(opts DumpOptions[K]) map[*ptrNode[K, V]]bool {
+	if opts.Path == nil {
+		return nil
+	}
+	result := make(map[*ptrNode[K, V]]bool)
+	loc := t.root
+	for !loc.isNil() {
+		result[loc.ptrNode] = true
+		switch c := t.cmp(*opts.Path, loc.key()); {
+		case c < 0:
+			loc = loc.left()
+		case c > 0:
+			loc = loc.right()
+		default:
+			return result
+		}
+	}
+	return result
+}
+
+func (t *Tree[K, V, Cmp]) dumpLabel(loc location[K, V], opts DumpOptions[K], sep string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%v", loc.key())
+	if !opts.HideValues {
+		fmt.Fprintf(&sb, ": %v", *loc.valuePtr())
+	}
+	fmt.Fprintf(&sb, "%sh=%d b=%d", sep, loc.height(), loc.balance())
+	if t.options.countChildren {
+		fmt.Fprintf(&sb, " c=%d", loc.childrenCount())
+	}
+	if t.weight != nil {
+		fmt.Fprintf(&sb, " w=%d", loc.subtreeWeight())
+	}
+	return sb.String()
+}
+
+// WriteDOT writes the structure of the tree to w in Graphviz DOT format.
+// Every node is labeled with its key, value, height, balance and, if enabled, children count and weight.
+// Time complexity: O(n).
+func (t *Tree[K, V, Cmp]) WriteDOT(w io.Writer, opts DumpOptions[K]) error {
+	dw := &dumpWriter{w: w}
+	path := t.onPath(opts)
+	dw.printf("digraph goavl {\n\tnode [shape=box, fontname=monospace];\n")
+	var nextID int
+	var visit func(loc location[K, V], depth int) int
+	visit = func(loc location[K, V], depth int) int {
+		nextID++
+		id := nextID
+		if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+			dw.printf("\tn%d [label=\"...\", shape=plaintext];\n", id)
+			return id
+		}
+		style := ""
+		if path[loc.ptrNode] {
+			style = ", style=filled, fillcolor=yellow"
+		}
+		dw.printf("\tn%d [label=\"%s\"%s];\n", id, dotEscape(t.dumpLabel(loc, opts, "\n")), style)
+		for _, child := range [...]struct {
+			loc   location[K, V]
+			label string
+		}{{loc.left(), "L"}, {loc.right(), "R"}} {
+			if child.loc.isNil() {
+				continue
+			}
+			childID := visit(child.loc, depth+1)
+			edgeStyle := ""
+			if path[loc.ptrNode] && path[child.loc.ptrNode] {
+				edgeStyle = ", color=red, penwidth=2"
+			}
+			dw.printf("\tn%d -> n%d [label=\"%s\"%s];\n", id, childID, child.label, edgeStyle)
+		}
+		return id
+	}
+	if !t.root.isNil() {
+		visit(t.root, 0)
+	}
+	dw.printf("}\n")
+	return dw.err
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// WriteASCII draws the tree sideways: the root is on the left, right subtrees are above their parents.
+// The nodes on the highlighted path are marked with '*'.
+// Time complexity: O(n).
+func (t *Tree[K, V, Cmp]) WriteASCII(w io.Writer, opts DumpOptions[K]) error {
+	dw := &dumpWriter{w: w}
+	path := t.onPath(opts)
+	var visit func(loc location[K, V], depth int, prefix, connector, upper, lower string)
+	visit = func(loc location[K, V], depth int, prefix, connector, upper, lower string) {
+		if opts.MaxDepth > 0 && depth >= opts.MaxDepth {
+			dw.printf("%s%s...\n", prefix, connector)
+			return
+		}
+		if right := loc.right(); !right.isNil() {
+			visit(right, depth+1, prefix+upper, "/-- ", "    ", "|   ")
+		}
+		mark := ""
+		if path[loc.ptrNode] {
+			mark = "*"
+		}
+		dw.printf("%s%s%s%s\n", prefix, connector, mark, t.dumpLabel(loc, opts, " "))
+		if left := loc.left(); !left.isNil() {
+			visit(left, depth+1, prefix+lower, "\\-- ", "|   ", "    ")
+		}
+	}
+	if !t.root.isNil() {
+		visit(t.root, 0, "", "", "", "")
+	}
+	return dw.err
+}
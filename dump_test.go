@@ -0,0 +1,72 @@
+package goavl
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeWriteASCII(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, string](WithCountChildren(true))
+	for i := 1; i <= 7; i++ {
+		tree.Insert(i, "v")
+	}
+	var buf bytes.Buffer
+	k := 5
+	a.NoError(tree.WriteASCII(&buf, DumpOptions[int]{Path: &k}))
+	a.Equal(`    /-- 7: v h=0 b=0 c=0
+/-- *6: v h=1 b=0 c=2
+|   \-- *5: v h=0 b=0 c=0
+*4: v h=2 b=0 c=6
+|   /-- 3: v h=0 b=0 c=0
+\-- 2: v h=1 b=0 c=2
+    \-- 1: v h=0 b=0 c=0
+`, buf.String())
+
+	buf.Reset()
+	a.NoError(tree.WriteASCII(&buf, DumpOptions[int]{MaxDepth: 1, HideValues: true}))
+	a.Equal(`/-- ...
+4 h=2 b=0 c=6
+\-- ...
+`, buf.String())
+
+	buf.Reset()
+	a.NoError(NewComparable[int, int]().WriteASCII(&buf, DumpOptions[int]{}))
+	a.Empty(buf.String())
+}
+
+func TestTreeWriteDOT(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[string, string]()
+	tree.Insert("b", `say "hi"`)
+	tree.Insert("a", "x")
+	tree.Insert("c", "y")
+	var buf bytes.Buffer
+	k := "a"
+	a.NoError(tree.WriteDOT(&buf, DumpOptions[string]{Path: &k}))
+	a.Equal(`digraph goavl {
+	node [shape=box, fontname=monospace];
+	n1 [label="b: say \"hi\"\nh=1 b=0", style=filled, fillcolor=yellow];
+	n2 [label="a: x\nh=0 b=0", style=filled, fillcolor=yellow];
+	n1 -> n2 [label="L", color=red, penwidth=2];
+	n3 [label="c: y\nh=0 b=0"];
+	n1 -> n3 [label="R"];
+}
+`, buf.String())
+
+	buf.Reset()
+	a.NoError(tree.WriteDOT(&buf, DumpOptions[string]{MaxDepth: 1}))
+	a.Equal(2, strings.Count(buf.String(), `label="...", shape=plaintext`))
+
+	a.Error(tree.WriteDOT(failingWriter{}, DumpOptions[string]{}))
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
@@ -0,0 +1,299 @@
+package goavl
+
+import (
+	"sync"
+	"time"
+)
+
+// WithClock sets the function that returns current time.
+// It's used by time-aware types, such as ExpiringTree, and allows to control time in tests.
+// The default is time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(o *Options) {
+		o.clock = now
+	}
+}
+
+// WithTTL sets the default time-to-live for the entries of an ExpiringTree.
+// Zero means that the entries inserted with Insert never expire.
+func WithTTL(ttl time.Duration) Option {
+	return func(o *Options) {
+		o.ttl = ttl
+	}
+}
+
+type expiringValue[V any] struct {
+	v        V
+	deadline time.Time
+}
+
+type expiryKey[K any] struct {
+	deadline time.Time
+	k        K
+}
+
+// ExpiringTree is a tree, where each entry can have an expiration time.
+// Expired entries are removed lazily, when they are accessed, by Sweep, or by a background janitor.
+// Expiration times are kept in a second time-ordered tree, so that Sweep doesn't scan live entries.
+// Unlike Tree, ExpiringTree is safe for concurrent use.
+type ExpiringTree[K, V any, Cmp func(a, b K) int] struct {
+	mu       sync.Mutex
+	data     *Tree[K, expiringValue[V], Cmp]
+	expiries *Tree[expiryKey[K], struct{}, func(a, b expiryKey[K]) int]
+	now      func() time.Time
+	ttl      time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewExpiring returns a new ExpiringTree.
+// Besides WithTTL and WithClock, the options affecting node allocation, counting and capacity are supported.
+// The callback set by WithEvictionCallback must be a func(k K, v V). It's called with the tree locked,
+// so it must not use the tree.
+// WithJournal, WithObserver, WithWeights and WithAfter are ignored.
+func NewExpiring[K, V any, Cmp func(a, b K) int](cmp Cmp, opts ...Option) *ExpiringTree[K, V, Cmp] {
+	options := newOptions(opts)
+	now, ttl := options.clock, options.ttl
+	options.journal, options.observers, options.weight = nil, nil, nil
+	options.clock, options.after, options.ttl = nil, nil, 0
+	onEvict := newEvictionCallback[K, V](options.onEvict)
+	var result *ExpiringTree[K, V, Cmp]
+	// evicted entries must leave the expiry index too, otherwise Sweep would delete a later entry with the same key.
+	options.onEvict = func(k K, ev expiringValue[V]) {
+		result.setDeadline(k, ev.deadline, time.Time{})
+		if onEvict != nil {
+			onEvict(k, ev.v)
+		}
+	}
+	result = &ExpiringTree[K, V, Cmp]{
+		data: newWithOptions[K, expiringValue[V]](cmp, options),
+		expiries: New[expiryKey[K], struct{}](func(a, b expiryKey[K]) int {
+			if c := a.deadline.Compare(b.deadline); c != 0 {
+				return c
+			}
+			return cmp(a.k, b.k)
+		}),
+		now: now,
+		ttl: ttl,
+	}
+	if result.now == nil {
+		result.now = time.Now
+	}
+	return result
+}
+
+// Insert inserts a kv pair with the default TTL set by WithTTL.
+// If the key is present, its value and expiration time are replaced.
+// Time complexity: O(logn).
+func (et *ExpiringTree[K, V, Cmp]) Insert(k K, v V) {
+	et.InsertWithTTL(k, v, et.ttl)
+}
+
+// InsertWithTTL inserts a kv pair that expires after ttl.
+// Zero ttl means that the entry never expires.
+// If the key is present, its value and expiration time are replaced.
+// If the tree is full, the expired entries are swept first, and then,
+// if it's still full, an entry is evicted or k is rejected, see WithCapacity.
+// Time complexity: O(logn).
+func (et *ExpiringTree[K, V, Cmp]) InsertWithTTL(k K, v V, ttl time.Duration) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	ev := expiringValue[V]{v: v, deadline: et.deadline(ttl)}
+	if ptr, found := et.data.Find(k); found {
+		et.setDeadline(k, ptr.deadline, ev.deadline)
+		*ptr = ev
+		return
+	}
+	if capacity := et.data.options.capacity; capacity > 0 && et.data.Len() >= capacity {
+		// expired entries must not take the place of live ones.
+		et.sweep(et.now())
+	}
+	if _, inserted := et.data.Insert(k, ev); inserted {
+		et.setDeadline(k, time.Time{}, ev.deadline)
+	}
+}
+
+// Touch sets the expiration time of k to now + ttl.
+// Zero ttl means that the entry never expires.
+// Returns false if k is not present or has already expired.
+// Time complexity: O(logn).
+func (et *ExpiringTree[K, V, Cmp]) Touch(k K, ttl time.Duration) bool {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	ptr, found := et.findLive(k)
+	if !found {
+		return false
+	}
+	deadline := et.deadline(ttl)
+	et.setDeadline(k, ptr.deadline, deadline)
+	ptr.deadline = deadline
+	return true
+}
+
+// Find returns a copy of the value for key k.
+// If the entry has expired, it's deleted and found is false.
+// Time complexity: O(logn).
+func (et *ExpiringTree[K, V, Cmp]) Find(k K) (v V, found bool) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	ptr, found := et.findLive(k)
+	if !found {
+		return v, false
+	}
+	return ptr.v, true
+}
+
+// ExpiresAt returns the expiration time of k.
+// Zero time is returned for the entries that never expire.
+// Time complexity: O(logn).
+func (et *ExpiringTree[K, V, Cmp]) ExpiresAt(k K) (deadline time.Time, found bool) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	ptr, found := et.findLive(k)
+	if !found {
+		return deadline, false
+	}
+	return ptr.deadline, true
+}
+
+// Delete deletes k from the tree.
+// Returns the value and true, if k was present and not expired.
+// Time complexity: O(logn).
+func (et *ExpiringTree[K, V, Cmp]) Delete(k K) (v V, deleted bool) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	ev, deleted := et.data.Delete(k)
+	if !deleted {
+		return v, false
+	}
+	et.setDeadline(k, ev.deadline, time.Time{})
+	if et.expired(ev.deadline, et.now()) {
+		return v, false
+	}
+	return ev.v, true
+}
+
+// Len returns the number of entries, including the expired ones that haven't been removed yet.
+func (et *ExpiringTree[K, V, Cmp]) Len() int {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	return et.data.Len()
+}
+
+// Range calls f for the entries that have not expired in ascending order, until f returns false.
+// f must not modify the tree.
+// Time complexity: O(n).
+func (et *ExpiringTree[K, V, Cmp]) Range(f func(k K, v V) bool) {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	now := et.now()
+	it := et.data.IteratorAtFirst()
+	for e, ok := it.Next(); ok; e, ok = it.Next() {
+		if !et.expired(e.Value.deadline, now) && !f(e.Key, e.Value.v) {
+			return
+		}
+	}
+}
+
+// Sweep deletes all the entries that expire at or before now.
+// Returns the number of deleted entries.
+// Time complexity: O(klogn), where k is the number of expired entries.
+func (et *ExpiringTree[K, V, Cmp]) Sweep(now time.Time) int {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	return et.sweep(now)
+}
+
+func (et *ExpiringTree[K, V, Cmp]) sweep(now time.Time) int {
+	var count int
+	for {
+		e, found := et.expiries.Min()
+		if !found || e.Key.deadline.After(now) {
+			return count
+		}
+		et.expiries.Delete(e.Key)
+		et.data.Delete(e.Key.k)
+		count++
+	}
+}
+
+// StartJanitor starts a goroutine that calls Sweep every interval.
+// Use Stop to stop it. Calling StartJanitor when a janitor is running is a noop.
+func (et *ExpiringTree[K, V, Cmp]) StartJanitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	if !et.startJanitor(ticker.C, ticker.Stop) {
+		ticker.Stop()
+	}
+}
+
+func (et *ExpiringTree[K, V, Cmp]) startJanitor(tick <-chan time.Time, cleanup func()) bool {
+	et.mu.Lock()
+	defer et.mu.Unlock()
+	if et.stop != nil {
+		return false
+	}
+	et.stop, et.done = make(chan struct{}), make(chan struct{})
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		defer cleanup()
+		for {
+			select {
+			case <-tick:
+				et.mu.Lock()
+				et.sweep(et.now())
+				et.mu.Unlock()
+			case <-stop:
+				return
+			}
+		}
+	}(et.stop, et.done)
+	return true
+}
+
+// Stop stops the janitor started by StartJanitor and waits for it to exit.
+// Calling Stop when no janitor is running is a noop.
+func (et *ExpiringTree[K, V, Cmp]) Stop() {
+	et.mu.Lock()
+	stop, done := et.stop, et.done
+	et.stop, et.done = nil, nil
+	et.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// findLive returns the entry for k, deleting it if it has expired.
+func (et *ExpiringTree[K, V, Cmp]) findLive(k K) (*expiringValue[V], bool) {
+	ptr, found := et.data.Find(k)
+	if !found {
+		return nil, false
+	}
+	if et.expired(ptr.deadline, et.now()) {
+		et.setDeadline(k, ptr.deadline, time.Time{})
+		et.data.Delete(k)
+		return nil, false
+	}
+	return ptr, true
+}
+
+func (et *ExpiringTree[K, V, Cmp]) expired(deadline, now time.Time) bool {
+	return !deadline.IsZero() && !deadline.After(now)
+}
+
+func (et *ExpiringTree[K, V, Cmp]) deadline(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return et.now().Add(ttl)
+}
+
+func (et *ExpiringTree[K, V, Cmp]) setDeadline(k K, oldDeadline, newDeadline time.Time) {
+	if !oldDeadline.IsZero() {
+		et.expiries.Delete(expiryKey[K]{deadline: oldDeadline, k: k})
+	}
+	if !newDeadline.IsZero() {
+		et.expiries.Insert(expiryKey[K]{deadline: newDeadline, k: k}, struct{}{})
+	}
+}
@@ -0,0 +1,204 @@
+package goavl
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestExpiringTreeLazyExpiry(t *testing.T) {
+	a := assert.New(t)
+	clock := newFakeClock()
+	tree := NewExpiring[int, string](intCmp, WithClock(clock.Now), WithTTL(time.Minute))
+	tree.Insert(1, "a")
+	tree.InsertWithTTL(2, "b", time.Hour)
+	tree.InsertWithTTL(3, "c", 0)
+
+	deadline, found := tree.ExpiresAt(1)
+	a.True(found)
+	a.Equal(clock.Now().Add(time.Minute), deadline)
+	deadline, found = tree.ExpiresAt(3)
+	a.True(found)
+	a.True(deadline.IsZero())
+
+	clock.Advance(time.Minute)
+	_, found = tree.Find(1)
+	a.False(found)
+	a.Equal(2, tree.Len())
+	v, found := tree.Find(2)
+	a.True(found)
+	a.Equal("b", v)
+
+	a.True(tree.Touch(2, 2*time.Hour))
+	a.False(tree.Touch(1, time.Hour))
+	clock.Advance(time.Hour)
+	_, found = tree.Find(2)
+	a.True(found)
+
+	tree.InsertWithTTL(3, "c2", time.Second)
+	clock.Advance(time.Second)
+	_, deleted := tree.Delete(3)
+	a.False(deleted)
+	v, deleted = tree.Delete(2)
+	a.True(deleted)
+	a.Equal("b", v)
+	a.Zero(tree.Len())
+	a.Zero(tree.expiries.Len())
+}
+
+func TestExpiringTreeSweep(t *testing.T) {
+	a := assert.New(t)
+	clock := newFakeClock()
+	tree := NewExpiring[int, int](intCmp, WithClock(clock.Now), WithCountChildren(true))
+	start := clock.Now()
+	for i := 0; i < 100; i++ {
+		tree.InsertWithTTL(i, i, time.Duration(100-i)*time.Second)
+	}
+	tree.Insert(1000, 1000)
+	// re-inserting replaces the deadline.
+	tree.InsertWithTTL(99, 99, time.Hour)
+
+	a.Equal(0, tree.Sweep(start))
+	a.Equal(49, tree.Sweep(start.Add(50*time.Second)))
+	a.Equal(52, tree.Len())
+	var keys []int
+	clock.Advance(100 * time.Second)
+	tree.Range(func(k, v int) bool {
+		keys = append(keys, k)
+		return true
+	})
+	a.Equal([]int{99, 1000}, keys)
+	a.Equal(50, tree.Sweep(clock.Now()))
+	a.Equal(2, tree.Len())
+	a.Equal(1, tree.expiries.Len())
+	a.NoError(checkHeightAndBalance(tree.data.root, true))
+}
+
+func TestExpiringTreeJanitor(t *testing.T) {
+	a := assert.New(t)
+	clock := newFakeClock()
+	tree := NewExpiring[int, int](intCmp, WithClock(clock.Now), WithTTL(time.Second))
+	for i := 0; i < 10; i++ {
+		tree.Insert(i, i)
+	}
+	tick := make(chan time.Time)
+	var cleanedUp bool
+	a.True(tree.startJanitor(tick, func() { cleanedUp = true }))
+	a.False(tree.startJanitor(tick, func() {}))
+
+	tick <- clock.Now()
+	clock.Advance(time.Second)
+	tick <- clock.Now()
+	// the second tick is processed before the janitor receives the third one.
+	tick <- clock.Now()
+	a.Zero(tree.Len())
+
+	tree.Stop()
+	tree.Stop()
+	a.True(cleanedUp)
+
+	tree.StartJanitor(time.Hour)
+	tree.Stop()
+}
+
+func TestExpiringTreeCapacity(t *testing.T) {
+	a := assert.New(t)
+	clock := newFakeClock()
+	var evicted []string
+	tree := NewExpiring[int, string](intCmp, WithClock(clock.Now), WithCapacity(1, EvictMin),
+		WithEvictionCallback(func(k int, v string) { evicted = append(evicted, fmt.Sprintf("%d=%s", k, v)) }))
+	tree.InsertWithTTL(1, "a", time.Second)
+	tree.InsertWithTTL(5, "b", 0)
+	a.Equal([]string{"1=a"}, evicted)
+	a.Zero(tree.expiries.Len())
+	_, deleted := tree.Delete(5)
+	a.True(deleted)
+
+	// the evicted entry's deadline must not apply to the re-inserted key.
+	tree.InsertWithTTL(1, "c", 0)
+	a.Zero(tree.Sweep(clock.Now().Add(2 * time.Second)))
+	v, found := tree.Find(1)
+	a.True(found)
+	a.Equal("c", v)
+
+	// a rejected key must not get into the expiry index.
+	tree.InsertWithTTL(0, "d", time.Second)
+	a.Equal(1, tree.Len())
+	a.Zero(tree.expiries.Len())
+}
+
+func TestExpiringTreeIgnoredOptions(t *testing.T) {
+	a := assert.New(t)
+	var buf bytes.Buffer
+	tree := NewExpiring[int, string](intCmp,
+		WithWeights(func(k int, v *string) uint64 { return uint64(len(*v)) }),
+		WithObserver[int, string](ObserverFuncs[int, string]{}),
+		WithJournal[int, string](&buf, JSONCodec[int]{}, JSONCodec[string]{}))
+	tree.Insert(1, "a")
+	v, found := tree.Find(1)
+	a.True(found)
+	a.Equal("a", v)
+	a.Zero(buf.Len())
+}
+
+func TestExpiringTreeCapacityExpired(t *testing.T) {
+	for _, policy := range []EvictPolicy{EvictMax, EvictMin} {
+		a := assert.New(t)
+		clock := newFakeClock()
+		var evicted []int
+		tree := NewExpiring[int, string](intCmp, WithClock(clock.Now), WithCapacity(2, policy),
+			WithEvictionCallback(func(k int, _ string) { evicted = append(evicted, k) }))
+		tree.InsertWithTTL(1, "a", time.Second)
+		tree.InsertWithTTL(2, "b", time.Second)
+		clock.Advance(time.Second)
+
+		// 0 would be rejected by EvictMin and 3 by EvictMax, if the expired entries took the place.
+		tree.InsertWithTTL(0, "c", 0)
+		tree.InsertWithTTL(3, "d", 0)
+		a.Empty(evicted)
+		a.Equal(2, tree.Len())
+		a.Zero(tree.expiries.Len())
+		for _, k := range []int{0, 3} {
+			_, found := tree.Find(k)
+			a.Truef(found, "policy %d, key %d", policy, k)
+		}
+	}
+}
+
+func TestTimeOptionsUnsupported(t *testing.T) {
+	a := assert.New(t)
+	for _, opt := range []Option{
+		WithClock(time.Now),
+		WithAfter(time.After),
+		WithTTL(time.Second),
+	} {
+		a.Panics(func() { New[int, int](intCmp, opt) })
+		a.NotPanics(func() { NewExpiring[int, int](intCmp, opt, WithCapacity(1, EvictMin)) })
+		a.NotPanics(func() { NewDelayQueue[int, int](opt) })
+	}
+}
@@ -0,0 +1,24 @@
+//go:build goexperiment.arenas
+
+package goavltest
+
+import (
+	"arena"
+	"testing"
+
+	"github.com/avdva/goavl"
+)
+
+func arenaOptions(tb testing.TB) []goavl.Option {
+	a := arena.NewArena()
+	tb.Cleanup(a.Free)
+	return []goavl.Option{goavl.WithArena(a), goavl.WithCountChildren(true)}
+}
+
+func TestCheckArenas(t *testing.T) {
+	Check(t, Config{New: newTreeFunc(arenaOptions), Runs: 5, Ops: 500, Seed: 42})
+}
+
+func FuzzTreeArenas(f *testing.F) {
+	Fuzz(f, Config{New: newTreeFunc(arenaOptions)})
+}
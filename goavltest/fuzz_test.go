@@ -0,0 +1,19 @@
+package goavltest
+
+import "testing"
+
+func FuzzTree(f *testing.F) {
+	Fuzz(f, Config{New: newTreeFunc(optionSets["Basic"])})
+}
+
+func FuzzTreeCountChildren(f *testing.F) {
+	Fuzz(f, Config{New: newTreeFunc(optionSets["CountChildren"])})
+}
+
+func FuzzTreeSyncPool(f *testing.F) {
+	Fuzz(f, Config{New: newTreeFunc(optionSets["SyncPool"])})
+}
+
+func FuzzTreeSyncPoolCountChildren(f *testing.F) {
+	Fuzz(f, Config{New: newTreeFunc(optionSets["SyncPoolCountChildren"])})
+}
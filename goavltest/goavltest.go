@@ -0,0 +1,278 @@
+// Package goavltest provides model-based testing of goavl trees.
+//
+// Random sequences of operations are applied both to a tree and to a reference model,
+// a sorted slice, and every observable result is compared after every step.
+// Failing sequences are shrunk to a minimal reproducer.
+// The package can be used to test wrappers around goavl.Tree, see Config.
+package goavltest
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/avdva/goavl"
+)
+
+// Tree is the type of the trees under test.
+type Tree = goavl.Tree[int, int, func(a, b int) int]
+
+// OpKind is a type of an operation.
+type OpKind uint8
+
+const (
+	// OpInsert inserts Key with value Arg.
+	OpInsert OpKind = iota
+	// OpDelete deletes Key.
+	OpDelete
+	// OpDeleteAt deletes the element at position Arg modulo Len.
+	OpDeleteAt
+	// OpDeleteIterator deletes the element pointed by LowerBound(Key).
+	OpDeleteIterator
+	// OpUpdateKey changes Key to Arg.
+	OpUpdateKey
+	// OpPopMin deletes the minimum.
+	OpPopMin
+	// OpPopMax deletes the maximum.
+	OpPopMax
+	// OpSetValue sets the value of Key to Arg via the pointer returned by Find.
+	OpSetValue
+	// OpAllMut iterates over the elements with keys <= Key with AllMut,
+	// deleting the ones where Key+Arg is even and adding Arg to the values of the others.
+	OpAllMut
+	// OpClear clears the tree.
+	OpClear
+
+	numOpKinds
+)
+
+var opNames = [...]string{
+	OpInsert:         "Insert",
+	OpDelete:         "Delete",
+	OpDeleteAt:       "DeleteAt",
+	OpDeleteIterator: "DeleteIterator",
+	OpUpdateKey:      "UpdateKey",
+	OpPopMin:         "PopMin",
+	OpPopMax:         "PopMax",
+	OpSetValue:       "SetValue",
+	OpAllMut:         "AllMut",
+	OpClear:          "Clear",
+}
+
+func (k OpKind) String() string {
+	if k < numOpKinds {
+		return opNames[k]
+	}
+	return fmt.Sprintf("OpKind(%d)", k)
+}
+
+// Op is a single operation on a tree.
+// After every operation, Key and Arg are also used as probe keys and positions for read-only queries.
+type Op struct {
+	Kind OpKind
+	Key  int
+	Arg  int
+}
+
+func (op Op) String() string {
+	return fmt.Sprintf("%v(%d, %d)", op.Kind, op.Key, op.Arg)
+}
+
+// GoString returns op as a Go literal, so that a reproducer can be pasted into a test.
+func (op Op) GoString() string {
+	return fmt.Sprintf("{Kind: goavltest.Op%v, Key: %d, Arg: %d}", op.Kind, op.Key, op.Arg)
+}
+
+// RandomOps returns n random operations with keys and arguments in [0, keySpace).
+func RandomOps(r *rand.Rand, n, keySpace int) []Op {
+	ops := make([]Op, n)
+	for i := range ops {
+		// inserts are more frequent than the other operations to let the tree grow,
+		// and Clear is rare.
+		kind := OpKind(r.Intn(int(numOpKinds) + 4))
+		if kind >= numOpKinds || (kind == OpClear && r.Intn(8) != 0) {
+			kind = OpInsert
+		}
+		ops[i] = Op{Kind: kind, Key: r.Intn(keySpace), Arg: r.Intn(keySpace)}
+	}
+	return ops
+}
+
+// DecodeOps converts fuzzer input into operations, three bytes per operation.
+// Keys and arguments are in [0, keySpace).
+func DecodeOps(data []byte, keySpace int) []Op {
+	ops := make([]Op, 0, len(data)/3)
+	for ; len(data) >= 3; data = data[3:] {
+		ops = append(ops, Op{
+			Kind: OpKind(data[0] % byte(numOpKinds)),
+			Key:  int(data[1]) % keySpace,
+			Arg:  int(data[2]) % keySpace,
+		})
+	}
+	return ops
+}
+
+// Config configures the tests.
+type Config struct {
+	// New returns an empty tree under test. It's called once per sequence of operations.
+	// The default is goavl.NewComparable[int, int]().
+	// The trees must not have a capacity set, as the model does not evict elements.
+	New func(tb testing.TB) *Tree
+	// Check, if set, is called after every operation.
+	// It allows to verify additional invariants, e.g. the state of a wrapper around the tree.
+	Check func(tree *Tree) error
+	// Runs is the number of random sequences checked by Check. The default is 10.
+	Runs int
+	// Ops is the length of a random sequence. The default is 1000.
+	Ops int
+	// KeySpace is the number of distinct keys. The default is 64.
+	KeySpace int
+	// Seed is the random seed. If zero, the current time is used.
+	Seed int64
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.New == nil {
+		cfg.New = func(testing.TB) *Tree {
+			return goavl.NewComparable[int, int]()
+		}
+	}
+	if cfg.Runs <= 0 {
+		cfg.Runs = 10
+	}
+	if cfg.Ops <= 0 {
+		cfg.Ops = 1000
+	}
+	if cfg.KeySpace <= 0 {
+		cfg.KeySpace = 64
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	return cfg
+}
+
+// Failure describes the operation after which the tree diverged from the model.
+type Failure struct {
+	// Step is the index of the operation.
+	Step int
+	Op   Op
+	Err  error
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("step %d, %v: %v", f.Step, f.Op, f.Err)
+}
+
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
+
+// Run applies ops to a new tree and to the model, comparing the results of all the operations
+// and the results of read-only queries after every step.
+// Returns a *Failure on the first mismatch. Panics are recovered and reported as failures.
+func Run(tb testing.TB, cfg Config, ops []Op) error {
+	cfg = cfg.withDefaults()
+	r := runner{tree: cfg.New(tb), check: cfg.Check}
+	for i, op := range ops {
+		if err := r.step(op); err != nil {
+			return &Failure{Step: i, Op: op, Err: err}
+		}
+	}
+	return nil
+}
+
+// Shrink returns a minimal subsequence of ops for which fails still returns true.
+// It removes chunks of operations, then tries to make keys and arguments smaller.
+// fails(ops) must be true.
+func Shrink(ops []Op, fails func(ops []Op) bool) []Op {
+	ops = append([]Op(nil), ops...)
+	for progress := true; progress; {
+		progress = false
+		for chunk := len(ops) / 2; chunk >= 1; chunk /= 2 {
+			for i := 0; i+chunk <= len(ops); {
+				candidate := append(append([]Op(nil), ops[:i]...), ops[i+chunk:]...)
+				if fails(candidate) {
+					ops = candidate
+					progress = true
+				} else {
+					i += chunk
+				}
+			}
+		}
+		for i := range ops {
+			for _, simpler := range simplerOps(ops[i]) {
+				orig := ops[i]
+				ops[i] = simpler
+				if fails(ops) {
+					progress = true
+					break
+				}
+				ops[i] = orig
+			}
+		}
+	}
+	return ops
+}
+
+func simplerOps(op Op) []Op {
+	var result []Op
+	if op.Kind != OpInsert {
+		result = append(result, Op{Kind: OpInsert, Key: op.Key, Arg: op.Arg})
+	}
+	if op.Key > 0 {
+		result = append(result, Op{Kind: op.Kind, Key: 0, Arg: op.Arg}, Op{Kind: op.Kind, Key: op.Key / 2, Arg: op.Arg})
+	}
+	if op.Arg > 0 {
+		result = append(result, Op{Kind: op.Kind, Key: op.Key, Arg: 0}, Op{Kind: op.Kind, Key: op.Key, Arg: op.Arg / 2})
+	}
+	return result
+}
+
+// Check runs cfg.Runs random sequences of operations.
+// On failure, the sequence is shrunk and reported together with the seed.
+func Check(t *testing.T, cfg Config) {
+	t.Helper()
+	cfg = cfg.withDefaults()
+	for run := 0; run < cfg.Runs; run++ {
+		seed := cfg.Seed + int64(run)
+		ops := RandomOps(rand.New(rand.NewSource(seed)), cfg.Ops, cfg.KeySpace)
+		if err := Run(t, cfg, ops); err == nil {
+			continue
+		}
+		ops = Shrink(ops, func(ops []Op) bool {
+			return Run(t, cfg, ops) != nil
+		})
+		t.Fatalf("seed %d: %v\nminimal reproducer:\n%s", seed, Run(t, cfg, ops), FormatOps(ops))
+	}
+}
+
+// Fuzz runs the fuzzer on sequences of operations decoded by DecodeOps.
+// Only cfg.New, cfg.Check and cfg.KeySpace are used.
+func Fuzz(f *testing.F, cfg Config) {
+	cfg = cfg.withDefaults()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 4; i++ {
+		seed := make([]byte, 3*(i+1)*16)
+		r.Read(seed)
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if err := Run(t, cfg, DecodeOps(data, cfg.KeySpace)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// FormatOps formats ops as a Go slice literal.
+func FormatOps(ops []Op) string {
+	var sb strings.Builder
+	sb.WriteString("[]goavltest.Op{\n")
+	for _, op := range ops {
+		fmt.Fprintf(&sb, "\t%#v,\n", op)
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
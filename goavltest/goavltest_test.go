@@ -0,0 +1,100 @@
+package goavltest
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/avdva/goavl"
+	"github.com/stretchr/testify/assert"
+)
+
+// optionSets are the option combinations checked by the tests and the fuzz targets.
+var optionSets = map[string]func(tb testing.TB) []goavl.Option{
+	"Basic": func(testing.TB) []goavl.Option {
+		return nil
+	},
+	"CountChildren": func(testing.TB) []goavl.Option {
+		return []goavl.Option{goavl.WithCountChildren(true)}
+	},
+	"SyncPool": func(testing.TB) []goavl.Option {
+		return []goavl.Option{goavl.WithSyncPool(nil)}
+	},
+	"SyncPoolCountChildren": func(testing.TB) []goavl.Option {
+		return []goavl.Option{goavl.WithSyncPool(&sync.Pool{}), goavl.WithCountChildren(true)}
+	},
+}
+
+func newTreeFunc(opts func(tb testing.TB) []goavl.Option) func(tb testing.TB) *Tree {
+	return func(tb testing.TB) *Tree {
+		return goavl.NewComparable[int, int](opts(tb)...)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for name, opts := range optionSets {
+		t.Run(name, func(t *testing.T) {
+			Check(t, Config{New: newTreeFunc(opts), Runs: 5, Ops: 500, Seed: 42})
+		})
+	}
+}
+
+func TestCheckParanoid(t *testing.T) {
+	Check(t, Config{
+		New: func(testing.TB) *Tree {
+			return goavl.NewComparable[int, int](goavl.WithCountChildren(true), goavl.WithParanoidChecks(true))
+		},
+		Runs:     2,
+		Ops:      300,
+		KeySpace: 16,
+		Seed:     7,
+	})
+}
+
+func TestRunDetectsFailures(t *testing.T) {
+	a := assert.New(t)
+	errTooBig := errors.New("too big")
+	cfg := Config{
+		Check: func(tree *Tree) error {
+			if tree.Len() > 3 {
+				return errTooBig
+			}
+			return nil
+		},
+	}
+	ops := RandomOps(rand.New(rand.NewSource(1)), 200, 32)
+	err := Run(t, cfg, ops)
+	var f *Failure
+	a.True(errors.As(err, &f))
+	a.True(errors.Is(err, errTooBig))
+
+	shrunk := Shrink(ops, func(ops []Op) bool {
+		return Run(t, cfg, ops) != nil
+	})
+	a.Error(Run(t, cfg, shrunk))
+	a.Len(shrunk, 4)
+	for _, op := range shrunk {
+		a.Equal(OpInsert, op.Kind)
+		a.Equal(0, op.Arg)
+	}
+}
+
+func TestRunRecoversPanics(t *testing.T) {
+	a := assert.New(t)
+	cfg := Config{
+		Check: func(tree *Tree) error {
+			tree.At(tree.Len())
+			return nil
+		},
+	}
+	err := Run(t, cfg, []Op{{Kind: OpInsert, Key: 1, Arg: 1}})
+	a.ErrorContains(err, "panic")
+}
+
+func TestDecodeOps(t *testing.T) {
+	a := assert.New(t)
+	ops := DecodeOps([]byte{0, 70, 3, byte(numOpKinds) + 1, 5, 6, 1}, 64)
+	a.Equal([]Op{{Kind: OpInsert, Key: 6, Arg: 3}, {Kind: OpDelete, Key: 5, Arg: 6}}, ops)
+	a.Equal("[]goavltest.Op{\n\t{Kind: goavltest.OpInsert, Key: 6, Arg: 3},\n\t{Kind: goavltest.OpDelete, Key: 5, Arg: 6},\n}", FormatOps(ops))
+}
@@ -0,0 +1,28 @@
+//go:build !go1.23
+
+package goavltest
+
+// allMut applies OpAllMut to the tree using iterators. Returns the number of visited elements.
+func allMut(tree *Tree, limit, arg int) int {
+	var visited int
+	it := tree.IteratorAtFirst()
+	for {
+		e, ok := it.Value()
+		if !ok || e.Key > limit {
+			break
+		}
+		visited++
+		if (e.Key+arg)%2 == 0 {
+			it = tree.DeleteIterator(it)
+		} else {
+			*e.Value += arg
+			it.Next()
+		}
+	}
+	return visited
+}
+
+// checkAll is a noop, as All requires Go 1.23.
+func checkAll(*Tree, []entry) error {
+	return nil
+}
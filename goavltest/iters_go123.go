@@ -0,0 +1,37 @@
+//go:build go1.23
+
+package goavltest
+
+import "fmt"
+
+// allMut applies OpAllMut to the tree using AllMut. Returns the number of visited elements.
+func allMut(tree *Tree, limit, arg int) int {
+	var visited int
+	for m := range tree.AllMut() {
+		if m.E.Key > limit {
+			break
+		}
+		visited++
+		if (m.E.Key+arg)%2 == 0 {
+			m.Delete()
+		} else {
+			*m.E.Value += arg
+		}
+	}
+	return visited
+}
+
+// checkAll compares the output of All with the model.
+func checkAll(tree *Tree, entries []entry) error {
+	var i int
+	for k, v := range tree.All() {
+		if i >= len(entries) || k != entries[i].k || v != entries[i].v {
+			return fmt.Errorf("All: unexpected element #%d: %d, %d", i, k, v)
+		}
+		i++
+	}
+	if i != len(entries) {
+		return fmt.Errorf("All returned %d elements; want %d", i, len(entries))
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package goavltest
+
+import "sort"
+
+type entry struct {
+	k, v int
+}
+
+// model is a reference implementation of a tree: a slice of entries sorted by key.
+type model struct {
+	entries []entry
+}
+
+// lowerBound returns the index of the first entry whose key is not less than k.
+func (m *model) lowerBound(k int) int {
+	return sort.Search(len(m.entries), func(i int) bool {
+		return m.entries[i].k >= k
+	})
+}
+
+// upperBound returns the index of the first entry whose key is greater than k.
+func (m *model) upperBound(k int) int {
+	return sort.Search(len(m.entries), func(i int) bool {
+		return m.entries[i].k > k
+	})
+}
+
+func (m *model) find(k int) (idx int, found bool) {
+	idx = m.lowerBound(k)
+	return idx, idx < len(m.entries) && m.entries[idx].k == k
+}
+
+// insert returns true, if a new entry was added.
+func (m *model) insert(k, v int) bool {
+	idx, found := m.find(k)
+	if found {
+		m.entries[idx].v = v
+		return false
+	}
+	m.entries = append(m.entries, entry{})
+	copy(m.entries[idx+1:], m.entries[idx:])
+	m.entries[idx] = entry{k: k, v: v}
+	return true
+}
+
+func (m *model) deleteAt(idx int) entry {
+	e := m.entries[idx]
+	m.entries = append(m.entries[:idx], m.entries[idx+1:]...)
+	return e
+}
+
+func (m *model) delete(k int) (v int, deleted bool) {
+	idx, found := m.find(k)
+	if !found {
+		return 0, false
+	}
+	return m.deleteAt(idx).v, true
+}
@@ -0,0 +1,315 @@
+package goavltest
+
+import (
+	"fmt"
+
+	"github.com/avdva/goavl"
+)
+
+type runner struct {
+	tree  *Tree
+	m     model
+	check func(tree *Tree) error
+}
+
+type iterator = goavl.Iterator[int, int, func(a, b int) int]
+
+func (r *runner) step(op Op) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	if err := r.apply(op); err != nil {
+		return err
+	}
+	if err := r.verify(op); err != nil {
+		return err
+	}
+	if r.check != nil {
+		return r.check(r.tree)
+	}
+	return nil
+}
+
+func (r *runner) apply(op Op) error {
+	switch op.Kind {
+	case OpInsert:
+		ptr, inserted := r.tree.Insert(op.Key, op.Arg)
+		wantInserted := r.m.insert(op.Key, op.Arg)
+		if ptr == nil || *ptr != op.Arg || inserted != wantInserted {
+			return fmt.Errorf("Insert = %v, %v; want %d, %v", deref(ptr), inserted, op.Arg, wantInserted)
+		}
+	case OpDelete:
+		v, deleted := r.tree.Delete(op.Key)
+		wantV, wantDeleted := r.m.delete(op.Key)
+		if v != wantV || deleted != wantDeleted {
+			return fmt.Errorf("Delete = %d, %v; want %d, %v", v, deleted, wantV, wantDeleted)
+		}
+	case OpDeleteAt:
+		if len(r.m.entries) == 0 {
+			return nil
+		}
+		pos := op.Arg % len(r.m.entries)
+		k, v := r.tree.DeleteAt(pos)
+		if want := r.m.deleteAt(pos); k != want.k || v != want.v {
+			return fmt.Errorf("DeleteAt(%d) = %d, %d; want %d, %d", pos, k, v, want.k, want.v)
+		}
+	case OpDeleteIterator:
+		next := r.tree.DeleteIterator(r.tree.LowerBound(op.Key))
+		idx := r.m.lowerBound(op.Key)
+		if idx < len(r.m.entries) {
+			r.m.deleteAt(idx)
+		}
+		return r.checkIterator("DeleteIterator", next, idx)
+	case OpUpdateKey:
+		ptr, updated := r.tree.UpdateKey(op.Key, op.Arg)
+		v, wantUpdated := r.m.delete(op.Key)
+		if wantUpdated {
+			r.m.insert(op.Arg, v)
+		}
+		if updated != wantUpdated || (updated && (ptr == nil || *ptr != v)) {
+			return fmt.Errorf("UpdateKey = %v, %v; want %d, %v", deref(ptr), updated, v, wantUpdated)
+		}
+	case OpPopMin, OpPopMax:
+		var k, v int
+		var found bool
+		idx := 0
+		if op.Kind == OpPopMin {
+			k, v, found = r.tree.PopMin()
+		} else {
+			k, v, found = r.tree.PopMax()
+			idx = len(r.m.entries) - 1
+		}
+		var want entry
+		wantFound := len(r.m.entries) > 0
+		if wantFound {
+			want = r.m.deleteAt(idx)
+		}
+		if k != want.k || v != want.v || found != wantFound {
+			return fmt.Errorf("%v = %d, %d, %v; want %d, %d, %v", op.Kind, k, v, found, want.k, want.v, wantFound)
+		}
+	case OpSetValue:
+		ptr, found := r.tree.Find(op.Key)
+		if found {
+			*ptr = op.Arg
+		}
+		idx, wantFound := r.m.find(op.Key)
+		if wantFound {
+			r.m.entries[idx].v = op.Arg
+		}
+		if found != wantFound {
+			return fmt.Errorf("Find = %v; want %v", found, wantFound)
+		}
+	case OpAllMut:
+		visited := allMut(r.tree, op.Key, op.Arg)
+		want := r.m.upperBound(op.Key)
+		for i := 0; i < len(r.m.entries) && r.m.entries[i].k <= op.Key; {
+			if (r.m.entries[i].k+op.Arg)%2 == 0 {
+				r.m.deleteAt(i)
+			} else {
+				r.m.entries[i].v += op.Arg
+				i++
+			}
+		}
+		if visited != want {
+			return fmt.Errorf("AllMut visited %d elements; want %d", visited, want)
+		}
+	case OpClear:
+		r.tree.Clear()
+		r.m.entries = nil
+	default:
+		return fmt.Errorf("unknown op kind %v", op.Kind)
+	}
+	return nil
+}
+
+// verify compares the results of all the read-only queries with the model.
+func (r *runner) verify(op Op) error {
+	if err := r.tree.Validate(); err != nil {
+		return err
+	}
+	entries := r.m.entries
+	if l := r.tree.Len(); l != len(entries) {
+		return fmt.Errorf("Len = %d; want %d", l, len(entries))
+	}
+	if err := r.verifyMinMax(); err != nil {
+		return err
+	}
+	if err := r.verifyWalk(); err != nil {
+		return err
+	}
+	if err := checkAll(r.tree, entries); err != nil {
+		return err
+	}
+	for _, q := range [...]int{op.Key, op.Arg} {
+		if err := r.verifyQueries(q); err != nil {
+			return err
+		}
+	}
+	if err := r.verifyRanges(op.Key, op.Arg); err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		pos := op.Arg % len(entries)
+		if e := r.tree.At(pos); e.Key != entries[pos].k || *e.Value != entries[pos].v {
+			return fmt.Errorf("At(%d) = %d, %d; want %d, %d", pos, e.Key, *e.Value, entries[pos].k, entries[pos].v)
+		}
+		if err := r.checkIterator("IteratorAt", r.tree.IteratorAt(pos), pos); err != nil {
+			return err
+		}
+		if err := r.checkIterator("AscendAt", r.tree.AscendAt(pos), pos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *runner) verifyMinMax() error {
+	entries := r.m.entries
+	found := len(entries) > 0
+	minE, minFound := r.tree.Min()
+	maxE, maxFound := r.tree.Max()
+	if minFound != found || maxFound != found {
+		return fmt.Errorf("Min, Max found = %v, %v; want %v", minFound, maxFound, found)
+	}
+	if found {
+		first, last := entries[0], entries[len(entries)-1]
+		if minE.Key != first.k || *minE.Value != first.v {
+			return fmt.Errorf("Min = %d, %d; want %d, %d", minE.Key, *minE.Value, first.k, first.v)
+		}
+		if maxE.Key != last.k || *maxE.Value != last.v {
+			return fmt.Errorf("Max = %d, %d; want %d, %d", maxE.Key, *maxE.Value, last.k, last.v)
+		}
+	}
+	return nil
+}
+
+// verifyWalk walks the tree forward and then back with the same iterator,
+// and backward from the end with a new one.
+func (r *runner) verifyWalk() error {
+	entries := r.m.entries
+	for _, it := range [...]iterator{r.tree.IteratorAtFirst(), r.tree.AscendFromStart()} {
+		for i := 0; ; i++ {
+			e, ok := it.Next()
+			if err := checkEntry("Next", e, ok, entries, i); err != nil || !ok {
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+		for i := len(entries) - 1; ; i-- {
+			e, ok := it.Prev()
+			if err := checkEntry("Prev", e, ok, entries, i); err != nil || !ok {
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	for _, it := range [...]iterator{r.tree.IteratorAtLast(), r.tree.DescendFromEnd()} {
+		for i := len(entries) - 1; ; i-- {
+			e, ok := it.Prev()
+			if err := checkEntry("Prev", e, ok, entries, i); err != nil || !ok {
+				if err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (r *runner) verifyQueries(q int) error {
+	entries := r.m.entries
+	idx, found := r.m.find(q)
+	ptr, ok := r.tree.Find(q)
+	if ok != found || (found && *ptr != entries[idx].v) {
+		return fmt.Errorf("Find(%d) = %v, %v; want %v", q, deref(ptr), ok, found)
+	}
+	rank, ok := r.tree.Rank(q)
+	if ok != found || (found && rank != idx) {
+		return fmt.Errorf("Rank(%d) = %d, %v; want %d, %v", q, rank, ok, idx, found)
+	}
+	lb, ub := r.m.lowerBound(q), r.m.upperBound(q)
+	for _, c := range [...]struct {
+		name string
+		it   iterator
+		idx  int
+	}{
+		{"LowerBound", r.tree.LowerBound(q), lb},
+		{"Ascend", r.tree.Ascend(q), lb},
+		{"UpperBound", r.tree.UpperBound(q), ub},
+		{"Floor", r.tree.Floor(q), ub - 1},
+		{"Descend", r.tree.Descend(q), ub - 1},
+	} {
+		if err := r.checkIterator(fmt.Sprintf("%s(%d)", c.name, q), c.it, c.idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *runner) verifyRanges(k1, k2 int) error {
+	wantCount := r.m.upperBound(k2) - r.m.lowerBound(k1)
+	if wantCount < 0 {
+		wantCount = 0
+	}
+	if count := r.tree.CountInRange(k1, k2); count != wantCount {
+		return fmt.Errorf("CountInRange(%d, %d) = %d; want %d", k1, k2, count, wantCount)
+	}
+	i1, found1 := r.m.find(k1)
+	i2, found2 := r.m.find(k2)
+	wantFound := found1 && found2
+	wantDistance := 0
+	if wantFound {
+		wantDistance = i2 - i1
+		if wantDistance < 0 {
+			wantDistance = -wantDistance
+		}
+	}
+	if distance, found := r.tree.RankDistance(k1, k2); distance != wantDistance || found != wantFound {
+		return fmt.Errorf("RankDistance(%d, %d) = %d, %v; want %d, %v", k1, k2, distance, found, wantDistance, wantFound)
+	}
+	return nil
+}
+
+// checkIterator checks that it points to the idx'th entry, or is invalid if idx is out of range,
+// and that it can move one step in both directions.
+func (r *runner) checkIterator(name string, it iterator, idx int) error {
+	entries := r.m.entries
+	e, ok := it.Value()
+	if err := checkEntry(name, e, ok, entries, idx); err != nil || !ok {
+		return err
+	}
+	back := it
+	it.Next()
+	e, ok = it.Value()
+	if err := checkEntry(name+" + Next", e, ok, entries, idx+1); err != nil {
+		return err
+	}
+	back.Prev()
+	e, ok = back.Value()
+	return checkEntry(name+" + Prev", e, ok, entries, idx-1)
+}
+
+func checkEntry(name string, e goavl.Entry[int, int], ok bool, entries []entry, idx int) error {
+	wantOK := idx >= 0 && idx < len(entries)
+	if ok != wantOK {
+		return fmt.Errorf("%s: found = %v; want %v", name, ok, wantOK)
+	}
+	if ok && (e.Key != entries[idx].k || *e.Value != entries[idx].v) {
+		return fmt.Errorf("%s = %d, %d; want %d, %d", name, e.Key, *e.Value, entries[idx].k, entries[idx].v)
+	}
+	return nil
+}
+
+func deref(ptr *int) any {
+	if ptr == nil {
+		return nil
+	}
+	return *ptr
+}
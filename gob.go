@@ -0,0 +1,73 @@
+package goavl
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+)
+
+// ErrNoComparator is returned when a tree without a comparator is being decoded.
+// Comparators can't be serialized, so the destination tree must be created with New or NewComparable.
+var ErrNoComparator = errors.New("goavl: the tree has no comparator, create it with New or NewComparable")
+
+// GobEncode implements gob.GobEncoder.
+// The entries are encoded one by one in ascending order.
+// Time complexity: O(n).
+func (t *Tree[K, V, Cmp]) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	if err := enc.Encode(t.length); err != nil {
+		return nil, err
+	}
+	it := t.IteratorAtFirst()
+	for e, ok := it.Next(); ok; e, ok = it.Next() {
+		if err := enc.Encode(e.Key); err != nil {
+			return nil, err
+		}
+		if err := enc.Encode(e.Value); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder.
+// The tree must be created with New or NewComparable, see DecodeGob.
+// Current contents of the tree are replaced only if all the entries are decoded successfully.
+// As the entries are encoded in order, the tree is rebuilt without rebalancing.
+// Returns ErrNotSorted if the encoded keys are not ascending according to the tree's comparator.
+// Time complexity: O(n).
+func (t *Tree[K, V, Cmp]) GobDecode(data []byte) error {
+	if t.cmp == nil {
+		return ErrNoComparator
+	}
+	dec := gob.NewDecoder(bytes.NewReader(data))
+	var length int
+	if err := dec.Decode(&length); err != nil {
+		return err
+	}
+	if length < 0 {
+		return fmt.Errorf("goavl: invalid encoded length %d", length)
+	}
+	return t.buildFromSorted(length, func() (k K, v V, err error) {
+		if err = dec.Decode(&k); err != nil {
+			return k, v, err
+		}
+		err = dec.Decode(&v)
+		return k, v, err
+	})
+}
+
+// DecodeGob reads the next gob value from dec into a new tree created with cmp and opts.
+//
+// Example:
+//
+//	tree, err := DecodeGob[int, string](gob.NewDecoder(r), intCmp, WithCountChildren(true)).
+func DecodeGob[K, V any, Cmp func(a, b K) int](dec *gob.Decoder, cmp Cmp, opts ...Option) (*Tree[K, V, Cmp], error) {
+	t := New[K, V](cmp, opts...)
+	if err := dec.Decode(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,73 @@
+package goavl
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeGob(t *testing.T) {
+	for _, count := range []int{0, 1, 2, 3, 7, 100, 1023} {
+		a := assert.New(t)
+		tree := NewComparable[int, string]()
+		for i := 0; i < count; i++ {
+			tree.Insert(i*2, string(rune('a'+i%26)))
+		}
+		var buf bytes.Buffer
+		a.NoError(gob.NewEncoder(&buf).Encode(tree))
+
+		decoded, err := DecodeGob[int, string](gob.NewDecoder(&buf), intCmp, WithCountChildren(true))
+		a.NoError(err)
+		assertTreeEqual(t, tree, decoded)
+		for i := 0; i < count; i++ {
+			a.Equal(i*2, decoded.At(i).Key)
+		}
+		if count > 0 {
+			first, _ := decoded.Min()
+			last, _ := decoded.Max()
+			a.Equal(0, first.Key)
+			a.Equal((count-1)*2, last.Key)
+		}
+		decoded.Insert(-1, "x")
+		decoded.Delete(0)
+		a.NoError(checkHeightAndBalance(decoded.root, decoded.options.countChildren))
+	}
+}
+
+func TestTreeGobReplacesContents(t *testing.T) {
+	a := assert.New(t)
+	src := NewComparable[string, int]()
+	src.Insert("b", 2)
+	src.Insert("a", 1)
+	data, err := src.GobEncode()
+	a.NoError(err)
+
+	dst := NewComparable[string, int]()
+	dst.Insert("z", 26)
+	a.NoError(dst.GobDecode(data))
+	assertTreeEqual(t, src, dst)
+}
+
+func TestTreeGobErrors(t *testing.T) {
+	a := assert.New(t)
+	src := NewComparable[int, int]()
+	for i := 0; i < 10; i++ {
+		src.Insert(i, i)
+	}
+	data, err := src.GobEncode()
+	a.NoError(err)
+
+	var zero Tree[int, int, func(a, b int) int]
+	a.True(errors.Is(zero.GobDecode(data), ErrNoComparator))
+
+	reversed := New[int, int](func(a, b int) int { return intCmp(b, a) })
+	a.True(errors.Is(reversed.GobDecode(data), ErrNotSorted))
+	a.Zero(reversed.Len())
+
+	truncated := NewComparable[int, int]()
+	a.Error(truncated.GobDecode(data[:len(data)-2]))
+	a.Zero(truncated.Len())
+}
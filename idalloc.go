@@ -0,0 +1,238 @@
+package goavl
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// IDAllocator hands out integer IDs from the range [lo, hi).
+// The free IDs are kept as a RangeSet of intervals, so the memory usage depends on
+// the fragmentation of the free space, not on the size of the range, which makes it
+// suitable for 64-bit ID spaces.
+// Create it with NewIDAllocator or decode it into a zero IDAllocator.
+// IDAllocator is not safe for concurrent use.
+type IDAllocator[T constraints.Integer] struct {
+	lo, hi  T
+	options Options
+	free    *RangeSet[T]
+	// bySize orders the free intervals by length, then by start, for best-fit block allocation.
+	// It's kept in sync with free by an observer.
+	bySize *Tree[Interval[T], struct{}, func(a, b Interval[T]) int]
+}
+
+// NewIDAllocator returns an allocator of the IDs from [lo, hi), all of which are free.
+// Only allocator options are supported: WithSyncPool and WithArena.
+func NewIDAllocator[T constraints.Integer](lo, hi T, opts ...Option) *IDAllocator[T] {
+	a := &IDAllocator[T]{options: allocatorOptions(newOptions(opts))}
+	a.reset(lo, hi)
+	a.free.Add(lo, hi)
+	return a
+}
+
+// reset makes the allocator manage [lo, hi) with no free IDs.
+func (a *IDAllocator[T]) reset(lo, hi T) {
+	a.lo, a.hi = lo, hi
+	a.bySize = newWithOptions[Interval[T], struct{}](compareIntervalsBySize[T], a.options)
+	options := a.options
+	options.observers = []any{Observer[T, T](ObserverFuncs[T, T]{
+		Insert: func(lo, hi T) {
+			a.bySize.Insert(Interval[T]{Lo: lo, Hi: hi}, struct{}{})
+		},
+		Update: func(lo, oldHi, newHi T) {
+			a.bySize.Delete(Interval[T]{Lo: lo, Hi: oldHi})
+			a.bySize.Insert(Interval[T]{Lo: lo, Hi: newHi}, struct{}{})
+		},
+		Delete: func(lo, hi T) {
+			a.bySize.Delete(Interval[T]{Lo: lo, Hi: hi})
+		},
+	})}
+	a.free = newRangeSet[T](options)
+}
+
+func compareIntervalsBySize[T constraints.Integer](a, b Interval[T]) int {
+	if c := compareIntegers(a.Len(), b.Len()); c != 0 {
+		return c
+	}
+	return compareIntegers(a.Lo, b.Lo)
+}
+
+// Bounds returns the range of the IDs managed by the allocator.
+func (a *IDAllocator[T]) Bounds() (lo, hi T) {
+	return a.lo, a.hi
+}
+
+// Alloc returns the lowest free ID and marks it as allocated.
+// Returns false if there are no free IDs.
+// Time complexity: O(logn), where n is the number of free intervals.
+func (a *IDAllocator[T]) Alloc() (id T, ok bool) {
+	loc := a.free.t.min
+	if loc.isNil() {
+		return id, false
+	}
+	id = loc.key()
+	a.free.Remove(id, id+1)
+	return id, true
+}
+
+// AllocN allocates a block of n consecutive IDs and returns the first of them.
+// The block is taken from the start of the shortest free interval that fits it,
+// or the lowest of such intervals, if there are several, which limits fragmentation.
+// Returns false if n <= 0 or there is no free interval of at least n IDs.
+// Time complexity: O(logm), where m is the number of free intervals.
+func (a *IDAllocator[T]) AllocN(n T) (first T, ok bool) {
+	if n <= 0 {
+		return first, false
+	}
+	var candidate location[Interval[T], struct{}]
+	for loc := a.bySize.root; !loc.isNil(); {
+		if loc.key().Len() >= n {
+			candidate = loc
+			loc = loc.left()
+		} else {
+			loc = loc.right()
+		}
+	}
+	if candidate.isNil() {
+		return first, false
+	}
+	first = candidate.key().Lo
+	a.free.Remove(first, first+n)
+	return first, true
+}
+
+// Release marks id as free.
+// Returns false if id is out of bounds or is not allocated.
+// Time complexity: O(logn), where n is the number of free intervals.
+func (a *IDAllocator[T]) Release(id T) bool {
+	if id < a.lo || id >= a.hi || a.free.Contains(id) {
+		return false
+	}
+	a.free.Add(id, id+1)
+	return true
+}
+
+// ReleaseN marks n consecutive IDs starting from first as free.
+// Returns false and does nothing, if any of them is out of bounds or is not allocated.
+// Time complexity: O(logm), where m is the number of free intervals.
+func (a *IDAllocator[T]) ReleaseN(first, n T) bool {
+	if n <= 0 || first < a.lo || first >= a.hi || n > a.hi-first || a.free.overlaps(first, first+n) {
+		return false
+	}
+	a.free.Add(first, first+n)
+	return true
+}
+
+// Reserve marks the IDs from [lo, hi) as allocated, for instance, the IDs restored from a storage.
+// Returns false and does nothing, if any of them is out of bounds or is already allocated.
+// Time complexity: O(logn), where n is the number of free intervals.
+func (a *IDAllocator[T]) Reserve(lo, hi T) bool {
+	if lo >= hi || lo < a.lo || hi > a.hi || !a.free.ContainsRange(lo, hi) {
+		return false
+	}
+	a.free.Remove(lo, hi)
+	return true
+}
+
+// IsAllocated returns true if id is in bounds and is allocated.
+// Time complexity: O(logn), where n is the number of free intervals.
+func (a *IDAllocator[T]) IsAllocated(id T) bool {
+	return id >= a.lo && id < a.hi && !a.free.Contains(id)
+}
+
+// Free returns the number of free IDs.
+// Time complexity: O(1).
+func (a *IDAllocator[T]) Free() T {
+	return a.free.Total()
+}
+
+// FreeBelow returns the number of free IDs less than x.
+// Time complexity: O(logn) with the goavl_weights build tag, O(n) otherwise,
+// where n is the number of free intervals.
+func (a *IDAllocator[T]) FreeBelow(x T) T {
+	return a.free.CountBelow(x)
+}
+
+// FreeIntervals returns the free IDs as intervals in ascending order.
+// Time complexity: O(n), where n is the number of free intervals.
+func (a *IDAllocator[T]) FreeIntervals() []Interval[T] {
+	return a.free.Intervals()
+}
+
+// idAllocatorState is the serialized form of IDAllocator.
+type idAllocatorState[T constraints.Integer] struct {
+	Lo   T      `json:"lo"`
+	Hi   T      `json:"hi"`
+	Free [][2]T `json:"free"`
+}
+
+func (a *IDAllocator[T]) state() idAllocatorState[T] {
+	state := idAllocatorState[T]{Lo: a.lo, Hi: a.hi, Free: make([][2]T, 0, a.free.Len())}
+	a.free.Each(func(i Interval[T]) bool {
+		state.Free = append(state.Free, [2]T{i.Lo, i.Hi})
+		return true
+	})
+	return state
+}
+
+// setState replaces the contents of the allocator.
+// The free intervals must be ascending, non-overlapping and within the bounds.
+func (a *IDAllocator[T]) setState(state idAllocatorState[T]) error {
+	if state.Lo > state.Hi {
+		return fmt.Errorf("goavl: invalid ID allocator bounds [%d, %d)", state.Lo, state.Hi)
+	}
+	prev := state.Lo
+	for _, i := range state.Free {
+		if i[0] < prev || i[0] >= i[1] || i[1] > state.Hi {
+			return fmt.Errorf("goavl: invalid free interval [%d, %d)", i[0], i[1])
+		}
+		prev = i[1]
+	}
+	a.reset(state.Lo, state.Hi)
+	for _, i := range state.Free {
+		a.free.Add(i[0], i[1])
+	}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+// The allocator is encoded as {"lo": lo, "hi": hi, "free": [[lo1, hi1], ...]}.
+// Time complexity: O(n), where n is the number of free intervals.
+func (a *IDAllocator[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.state())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// Current contents of the allocator are replaced.
+// Time complexity: O(nlogn), where n is the number of free intervals.
+func (a *IDAllocator[T]) UnmarshalJSON(data []byte) error {
+	var state idAllocatorState[T]
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	return a.setState(state)
+}
+
+// GobEncode implements gob.GobEncoder.
+// Time complexity: O(n), where n is the number of free intervals.
+func (a *IDAllocator[T]) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(a.state()); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder.
+// Current contents of the allocator are replaced.
+// Time complexity: O(nlogn), where n is the number of free intervals.
+func (a *IDAllocator[T]) GobDecode(data []byte) error {
+	var state idAllocatorState[T]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&state); err != nil {
+		return err
+	}
+	return a.setState(state)
+}
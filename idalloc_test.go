@@ -0,0 +1,208 @@
+package goavl
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// validateIDAllocator checks that the size index matches the free intervals.
+func validateIDAllocator[T int | uint64](a *assert.Assertions, alloc *IDAllocator[T]) {
+	a.NoError(alloc.free.t.Validate())
+	a.NoError(alloc.bySize.Validate())
+	a.Equal(alloc.free.Len(), alloc.bySize.Len())
+	alloc.free.Each(func(i Interval[T]) bool {
+		_, found := alloc.bySize.Find(i)
+		a.Truef(found, "%v is not indexed", i)
+		return true
+	})
+}
+
+func TestIDAllocator(t *testing.T) {
+	a := assert.New(t)
+	alloc := NewIDAllocator[int](10, 20)
+	lo, hi := alloc.Bounds()
+	a.Equal(10, lo)
+	a.Equal(20, hi)
+	a.Equal(10, alloc.Free())
+	for want := 10; want < 13; want++ {
+		id, ok := alloc.Alloc()
+		a.True(ok)
+		a.Equal(want, id)
+	}
+	a.True(alloc.IsAllocated(11))
+	a.False(alloc.IsAllocated(13))
+	a.False(alloc.IsAllocated(5))
+	a.True(alloc.Release(11))
+	a.False(alloc.Release(11))
+	a.False(alloc.Release(15))
+	a.False(alloc.Release(25))
+	id, _ := alloc.Alloc()
+	a.Equal(11, id)
+
+	a.True(alloc.Reserve(15, 17))
+	a.False(alloc.Reserve(16, 18))
+	a.False(alloc.Reserve(19, 21))
+	a.False(alloc.Reserve(5, 5))
+	a.Equal([]Interval[int]{iv(13, 15), iv(17, 20)}, alloc.FreeIntervals())
+	a.Equal(5, alloc.Free())
+	a.Equal(2, alloc.FreeBelow(17))
+	a.Equal(4, alloc.FreeBelow(19))
+	validateIDAllocator(a, alloc)
+
+	// The shortest fitting interval is used.
+	first, ok := alloc.AllocN(2)
+	a.True(ok)
+	a.Equal(13, first)
+	_, ok = alloc.AllocN(4)
+	a.False(ok)
+	_, ok = alloc.AllocN(0)
+	a.False(ok)
+	first, ok = alloc.AllocN(3)
+	a.True(ok)
+	a.Equal(17, first)
+	_, ok = alloc.Alloc()
+	a.False(ok)
+	a.Zero(alloc.Free())
+
+	a.True(alloc.ReleaseN(14, 4))
+	a.False(alloc.ReleaseN(13, 2))
+	a.False(alloc.ReleaseN(19, 2))
+	a.False(alloc.ReleaseN(5, 6))
+	a.Equal([]Interval[int]{iv(14, 18)}, alloc.FreeIntervals())
+	validateIDAllocator(a, alloc)
+}
+
+func TestIDAllocatorRandom(t *testing.T) {
+	a := assert.New(t)
+	r := rand.New(rand.NewSource(17))
+	const size = 300
+	var used [size]bool
+	alloc := NewIDAllocator[int](0, size)
+	for i := 0; i < 5000; i++ {
+		switch op := r.Intn(6); op {
+		case 0:
+			id, ok := alloc.Alloc()
+			lowest := -1
+			for x := range used {
+				if !used[x] {
+					lowest = x
+					break
+				}
+			}
+			a.Equal(lowest >= 0, ok)
+			if ok {
+				a.Equal(lowest, id)
+				used[id] = true
+			}
+		case 1:
+			n := r.Intn(10) + 1
+			first, ok := alloc.AllocN(n)
+			if ok {
+				for x := first; x < first+n; x++ {
+					a.False(used[x])
+					used[x] = true
+				}
+			}
+		case 2, 3:
+			id := r.Intn(size)
+			a.Equal(used[id], alloc.Release(id))
+			used[id] = false
+		case 4:
+			lo := r.Intn(size)
+			hi := lo + r.Intn(5) + 1
+			free := hi <= size
+			for x := lo; free && x < hi; x++ {
+				free = !used[x]
+			}
+			a.Equal(free, alloc.Reserve(lo, hi))
+			for x := lo; free && x < hi; x++ {
+				used[x] = true
+			}
+		default:
+			first, n := r.Intn(size), r.Intn(5)+1
+			allUsed := first+n <= size
+			for x := first; allUsed && x < first+n; x++ {
+				allUsed = used[x]
+			}
+			a.Equal(allUsed, alloc.ReleaseN(first, n))
+			for x := first; allUsed && x < first+n; x++ {
+				used[x] = false
+			}
+		}
+		if i%100 != 0 {
+			continue
+		}
+		validateIDAllocator(a, alloc)
+		free, x := 0, r.Intn(size)
+		for id := range used {
+			a.Equal(used[id], alloc.IsAllocated(id))
+			if !used[id] && id < x {
+				free++
+			}
+		}
+		a.Equal(free, alloc.FreeBelow(x))
+	}
+}
+
+func TestIDAllocatorWide(t *testing.T) {
+	a := assert.New(t)
+	alloc := NewIDAllocator[uint64](1, math.MaxUint64)
+	first, ok := alloc.AllocN(1 << 62)
+	a.True(ok)
+	a.Equal(uint64(1), first)
+	a.True(alloc.Reserve(math.MaxUint64-10, math.MaxUint64))
+	a.Equal(uint64(math.MaxUint64-1-1<<62-10), alloc.Free())
+	a.True(alloc.Release(1 << 40))
+	id, _ := alloc.Alloc()
+	a.Equal(uint64(1<<40), id)
+	a.False(alloc.ReleaseN(math.MaxUint64-5, 10))
+	a.True(alloc.ReleaseN(math.MaxUint64-5, 5))
+	validateIDAllocator(a, alloc)
+}
+
+func TestIDAllocatorEncoding(t *testing.T) {
+	a := assert.New(t)
+	alloc := NewIDAllocator[int](0, 100)
+	alloc.AllocN(10)
+	alloc.Reserve(50, 60)
+	alloc.Release(5)
+
+	data, err := json.Marshal(alloc)
+	a.NoError(err)
+	a.JSONEq(`{"lo":0,"hi":100,"free":[[5,6],[10,50],[60,100]]}`, string(data))
+	var decoded IDAllocator[int]
+	a.NoError(json.Unmarshal(data, &decoded))
+	a.Equal(alloc.FreeIntervals(), decoded.FreeIntervals())
+	validateIDAllocator(a, &decoded)
+	// [10, 50) and [60, 100) have the same length, the lower one is used.
+	first, _ := decoded.AllocN(2)
+	a.Equal(10, first)
+
+	var buf bytes.Buffer
+	a.NoError(gob.NewEncoder(&buf).Encode(alloc))
+	decoded2 := NewIDAllocator[int](0, 1)
+	a.NoError(gob.NewDecoder(&buf).Decode(decoded2))
+	lo, hi := decoded2.Bounds()
+	a.Equal([]int{0, 100}, []int{lo, hi})
+	a.Equal(alloc.FreeIntervals(), decoded2.FreeIntervals())
+	validateIDAllocator(a, decoded2)
+
+	before := decoded.FreeIntervals()
+	for _, s := range []string{
+		`{"lo":10,"hi":0}`,
+		`{"lo":0,"hi":10,"free":[[5,3]]}`,
+		`{"lo":0,"hi":10,"free":[[5,8],[7,9]]}`,
+		`{"lo":0,"hi":10,"free":[[5,11]]}`,
+		`{"lo":5,"hi":10,"free":[[0,6]]}`,
+		`[]`,
+	} {
+		a.Errorf(json.Unmarshal([]byte(s), &decoded), "%s", s)
+	}
+	a.Equal(before, decoded.FreeIntervals())
+}
@@ -0,0 +1,84 @@
+package goavl
+
+// subtreeHeight returns the height of the subtree rooted at loc, or -1 for an empty one.
+func subtreeHeight[K, V any](loc location[K, V]) int {
+	if loc.isNil() {
+		return -1
+	}
+	return int(loc.height())
+}
+
+// subtreeLen returns the number of nodes in the subtree rooted at loc.
+// Requires children counts.
+func subtreeLen[K, V any](loc location[K, V]) int {
+	if loc.isNil() {
+		return 0
+	}
+	return int(loc.childrenCount()) + 1
+}
+
+// joinRoots builds a balanced tree from the nodes of the subtree l, the detached node mid,
+// and the subtree r, in this order, and makes it the root of t.
+// The parent links of l and r are ignored. Length, min and max of t are not updated.
+// Time complexity: O(|height(l) - height(r)| + 1).
+func (t *Tree[K, V, Cmp]) joinRoots(l, mid, r location[K, V]) location[K, V] {
+	hl, hr := subtreeHeight(l), subtreeHeight(r)
+	switch {
+	case hl > hr+1:
+		// go down the right spine of l to the first node, which is not higher than r + 1.
+		parent, c := l, l.right()
+		for subtreeHeight(c) > hr+1 {
+			parent, c = c, c.right()
+		}
+		t.setRoot(l)
+		t.attachMid(c, mid, r)
+		parent.setRight(mid)
+		t.checkBalance(parent, true)
+	case hr > hl+1:
+		parent, c := r, r.left()
+		for subtreeHeight(c) > hl+1 {
+			parent, c = c, c.left()
+		}
+		t.setRoot(r)
+		t.attachMid(l, mid, c)
+		parent.setLeft(mid)
+		t.checkBalance(parent, true)
+	default:
+		t.attachMid(l, mid, r)
+		t.setRoot(mid)
+	}
+	return t.root
+}
+
+func (t *Tree[K, V, Cmp]) attachMid(l, mid, r location[K, V]) {
+	mid.ptrNode.left = location[K, V]{}
+	mid.ptrNode.right = location[K, V]{}
+	mid.setLeft(l)
+	mid.setRight(r)
+	mid.recalcHeight()
+	t.recalcAugments(mid)
+}
+
+// splitRoot splits the subtree rooted at loc into the subtrees containing its first n nodes and the rest.
+// Requires children counts. t.root is used as a scratch space and must be reset by the caller.
+// Time complexity: O(logn).
+func (t *Tree[K, V, Cmp]) splitRoot(loc location[K, V], n int) (l, r location[K, V]) {
+	if loc.isNil() {
+		return l, r
+	}
+	left, right := loc.left(), loc.right()
+	if leftLen := subtreeLen(left); n <= leftLen {
+		l, r = t.splitRoot(left, n)
+		return l, t.joinRoots(r, loc, right)
+	}
+	l, r = t.splitRoot(right, n-subtreeLen(left)-1)
+	return t.joinRoots(left, loc, l), r
+}
+
+// detachRoot makes loc a root of a separate tree.
+func detachRoot[K, V any](loc location[K, V]) location[K, V] {
+	if !loc.isNil() {
+		loc.setParent(location[K, V]{})
+	}
+	return loc
+}
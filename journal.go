@@ -0,0 +1,419 @@
+package goavl
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	journalOpInsert byte = iota + 1
+	journalOpDelete
+	journalOpUpdateKey
+	journalOpClear
+)
+
+const maxJournalRecordSize = 1 << 30
+
+var (
+	// ErrJournalCorrupt is returned by Replay if a record in the middle of the journal is damaged.
+	ErrJournalCorrupt = errors.New("goavl: journal is corrupt")
+	// ErrNoJournal is returned by journal operations on a tree created without WithJournal.
+	ErrNoJournal = errors.New("goavl: the tree has no journal")
+	// ErrJournalNotTruncatable is returned by Checkpoint if the journal writer
+	// is not a file that can be replaced, like *os.File.
+	ErrJournalNotTruncatable = errors.New("goavl: the journal can't be truncated")
+)
+
+// Codec converts values of type T to bytes and back.
+type Codec[T any] interface {
+	Encode(v T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
+
+// JSONCodec is a Codec that uses encoding/json.
+type JSONCodec[T any] struct{}
+
+// Encode returns the JSON encoding of v.
+func (JSONCodec[T]) Encode(v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Decode parses JSON-encoded data.
+func (JSONCodec[T]) Decode(data []byte) (v T, err error) {
+	err = json.Unmarshal(data, &v)
+	return v, err
+}
+
+// WithJournal makes the tree append every mutation to w before the mutating method returns.
+// Insert, Delete, DeleteAt, DeleteIterator, UpdateKey and Clear are journaled.
+// Keys and values are encoded with kc and vc. K and V must match the tree's type parameters.
+// Use Replay to restore the tree from the journal and Checkpoint to compact it.
+// Write errors do not fail the mutations; once a write fails, journaling stops and
+// the error is reported by JournalErr.
+func WithJournal[K, V any](w io.Writer, kc Codec[K], vc Codec[V]) Option {
+	return func(o *Options) {
+		o.journal = &journal[K, V]{w: w, kc: kc, vc: vc}
+	}
+}
+
+type journal[K, V any] struct {
+	w   io.Writer
+	kc  Codec[K]
+	vc  Codec[V]
+	buf []byte
+	err error
+	// replaying is set during Replay so that the replayed mutations are not written again.
+	replaying bool
+}
+
+func newJournal[K, V any](o any) *journal[K, V] {
+	if o == nil {
+		return nil
+	}
+	j, ok := o.(*journal[K, V])
+	if !ok {
+		panic(fmt.Sprintf("goavl: journal type %T doesn't match the tree", o))
+	}
+	return j
+}
+
+func (j *journal[K, V]) active() bool {
+	return j != nil && j.err == nil && !j.replaying
+}
+
+// write appends a record consisting of the op and the given fields.
+// Record layout: uvarint payload length, crc32 of the payload (little endian), payload.
+// Payload layout: op, then a uvarint length and the bytes for every field.
+func (j *journal[K, V]) write(op byte, fields ...[]byte) {
+	size := 1
+	for _, f := range fields {
+		size += binary.MaxVarintLen64 + len(f)
+	}
+	payload := make([]byte, 0, size)
+	payload = append(payload, op)
+	for _, f := range fields {
+		payload = binary.AppendUvarint(payload, uint64(len(f)))
+		payload = append(payload, f...)
+	}
+	j.buf = binary.AppendUvarint(j.buf[:0], uint64(len(payload)))
+	j.buf = binary.LittleEndian.AppendUint32(j.buf, crc32.ChecksumIEEE(payload))
+	j.buf = append(j.buf, payload...)
+	_, j.err = j.w.Write(j.buf)
+}
+
+func (j *journal[K, V]) insert(k K, v V) {
+	if !j.active() {
+		return
+	}
+	kb, err := j.kc.Encode(k)
+	if err != nil {
+		j.err = err
+		return
+	}
+	vb, err := j.vc.Encode(v)
+	if err != nil {
+		j.err = err
+		return
+	}
+	j.write(journalOpInsert, kb, vb)
+}
+
+func (j *journal[K, V]) delete(k K) {
+	if !j.active() {
+		return
+	}
+	kb, err := j.kc.Encode(k)
+	if err != nil {
+		j.err = err
+		return
+	}
+	j.write(journalOpDelete, kb)
+}
+
+func (j *journal[K, V]) updateKey(oldKey, newKey K) {
+	if !j.active() {
+		return
+	}
+	ob, err := j.kc.Encode(oldKey)
+	if err != nil {
+		j.err = err
+		return
+	}
+	nb, err := j.kc.Encode(newKey)
+	if err != nil {
+		j.err = err
+		return
+	}
+	j.write(journalOpUpdateKey, ob, nb)
+}
+
+func (j *journal[K, V]) clear() {
+	if !j.active() {
+		return
+	}
+	j.write(journalOpClear)
+}
+
+// JournalErr returns the first error that occurred while writing the journal.
+func (t *Tree[K, V, Cmp]) JournalErr() error {
+	if t.journal == nil {
+		return nil
+	}
+	return t.journal.err
+}
+
+// Replay applies the mutations read from r to the tree.
+// The tree must be created with WithJournal, its codecs are used to decode the records.
+// Records are not written to the tree's own journal while replaying.
+// A torn final record, for example one left by a crash in the middle of a write, is ignored,
+// as well as zero bytes following it, which some file systems leave after a crash.
+// If r is the tree's journal writer and it can be truncated, the torn record is cut off,
+// so new records are appended right after the last valid one.
+// Returns ErrJournalCorrupt if a damaged record is followed by more non-zero data.
+func (t *Tree[K, V, Cmp]) Replay(r io.Reader) error {
+	if t.journal == nil {
+		return ErrNoJournal
+	}
+	kc, vc := t.journal.kc, t.journal.vc
+	t.journal.replaying = true
+	defer func() { t.journal.replaying = false }()
+	br := bufio.NewReader(r)
+	var valid int64
+	for {
+		payload, n, err := readJournalRecord(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			torn, tailErr := onlyZeros(br)
+			if tailErr != nil {
+				return tailErr
+			}
+			if !torn {
+				return fmt.Errorf("%w: at offset %d: %v", ErrJournalCorrupt, valid, err)
+			}
+			return t.truncateJournal(r, valid)
+		}
+		if err := t.applyJournalRecord(payload, kc, vc); err != nil {
+			return fmt.Errorf("%w: at offset %d: %v", ErrJournalCorrupt, valid, err)
+		}
+		valid += n
+	}
+}
+
+func (t *Tree[K, V, Cmp]) truncateJournal(r io.Reader, size int64) error {
+	if any(r) != any(t.journal.w) {
+		return nil
+	}
+	tr, ok := t.journal.w.(journalTruncater)
+	if !ok {
+		return nil
+	}
+	if err := tr.Truncate(size); err != nil {
+		return err
+	}
+	_, err := tr.Seek(size, io.SeekStart)
+	return err
+}
+
+// onlyZeros reads r to the end and returns true if all the bytes are zero.
+func onlyZeros(r io.Reader) (bool, error) {
+	var buf [4096]byte
+	for {
+		n, err := r.Read(buf[:])
+		for _, b := range buf[:n] {
+			if b != 0 {
+				return false, nil
+			}
+		}
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+}
+
+// readJournalRecord returns the payload of the next record and the total size of the record.
+// Returns io.EOF if there are no more records.
+func readJournalRecord(br *bufio.Reader) (payload []byte, n int64, err error) {
+	if _, err := br.Peek(1); err != nil {
+		return nil, 0, err
+	}
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	if size == 0 || size > maxJournalRecordSize {
+		return nil, 0, fmt.Errorf("invalid record size %d", size)
+	}
+	var crc [4]byte
+	if _, err := io.ReadFull(br, crc[:]); err != nil {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	payload = make([]byte, size)
+	if _, err := io.ReadFull(br, payload); err != nil {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(crc[:]) {
+		return nil, 0, errors.New("checksum mismatch")
+	}
+	var sizeBuf [binary.MaxVarintLen64]byte
+	n = int64(binary.PutUvarint(sizeBuf[:], size)) + int64(len(crc)) + int64(size)
+	return payload, n, nil
+}
+
+func (t *Tree[K, V, Cmp]) applyJournalRecord(payload []byte, kc Codec[K], vc Codec[V]) error {
+	op, fields := payload[0], payload[1:]
+	var parts [2][]byte
+	var count int
+	for len(fields) > 0 {
+		size, n := binary.Uvarint(fields)
+		if n <= 0 || uint64(len(fields)-n) < size || count == len(parts) {
+			return errors.New("malformed record")
+		}
+		parts[count] = fields[n : n+int(size)]
+		fields = fields[n+int(size):]
+		count++
+	}
+	switch {
+	case op == journalOpInsert && count == 2:
+		k, err := kc.Decode(parts[0])
+		if err != nil {
+			return err
+		}
+		v, err := vc.Decode(parts[1])
+		if err != nil {
+			return err
+		}
+		t.Insert(k, v)
+	case op == journalOpDelete && count == 1:
+		k, err := kc.Decode(parts[0])
+		if err != nil {
+			return err
+		}
+		t.Delete(k)
+	case op == journalOpUpdateKey && count == 2:
+		oldKey, err := kc.Decode(parts[0])
+		if err != nil {
+			return err
+		}
+		newKey, err := kc.Decode(parts[1])
+		if err != nil {
+			return err
+		}
+		t.UpdateKey(oldKey, newKey)
+	case op == journalOpClear && count == 0:
+		t.Clear()
+	default:
+		return fmt.Errorf("unknown record type %d", op)
+	}
+	return nil
+}
+
+type journalTruncater interface {
+	Truncate(size int64) error
+	Seek(offset int64, whence int) (int64, error)
+}
+
+// journalFile is a journal writer that Checkpoint can replace, like *os.File.
+type journalFile interface {
+	io.WriteCloser
+	Name() string
+	Sync() error
+}
+
+// JournalWriter returns the current journal writer, or nil if the tree has no journal.
+// It differs from the writer passed to WithJournal after a Checkpoint.
+func (t *Tree[K, V, Cmp]) JournalWriter() io.Writer {
+	if t.journal == nil {
+		return nil
+	}
+	return t.journal.w
+}
+
+// Checkpoint replaces the contents of the journal with a snapshot of the tree.
+// The journal writer must be a file, like *os.File, having Name, Sync and Close methods.
+// The snapshot is written to a temporary file in the same directory, which is synced and
+// renamed over the journal, so a crash during Checkpoint leaves either the old or the new journal.
+// On success the old writer is closed and the new file becomes the journal writer, see JournalWriter.
+// The caller is responsible for closing it. On failure the old journal is kept.
+// Time complexity: O(n).
+func (t *Tree[K, V, Cmp]) Checkpoint() error {
+	j := t.journal
+	if j == nil {
+		return ErrNoJournal
+	}
+	if j.err != nil {
+		return j.err
+	}
+	old, ok := j.w.(journalFile)
+	if !ok {
+		return ErrJournalNotTruncatable
+	}
+	f, err := t.writeSnapshot(old.Name())
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), old.Name()); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	syncDir(filepath.Dir(old.Name()))
+	j.w = f
+	_ = old.Close()
+	return nil
+}
+
+// writeSnapshot writes the elements of the tree to a new synced file next to the journal at path.
+// The file has the permissions of the journal.
+func (t *Tree[K, V, Cmp]) writeSnapshot(path string) (_ *os.File, err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".checkpoint-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+	if info, err := os.Stat(path); err == nil {
+		if err := f.Chmod(info.Mode().Perm()); err != nil {
+			return nil, err
+		}
+	}
+	j := t.journal
+	w := j.w
+	j.w = f
+	it := t.IteratorAtFirst()
+	for e, ok := it.Next(); ok && j.err == nil; e, ok = it.Next() {
+		j.insert(e.Key, *e.Value)
+	}
+	err = j.err
+	j.w, j.err = w, nil
+	if err != nil {
+		return nil, err
+	}
+	return f, f.Sync()
+}
+
+// syncDir makes a rename in dir durable. Errors are ignored,
+// as some platforms don't support syncing directories.
+func syncDir(dir string) {
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
@@ -0,0 +1,241 @@
+package goavl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newJournaledTree(f *os.File) *Tree[int, string, func(a, b int) int] {
+	return NewComparable[int, string](WithCountChildren(true), WithJournal[int, string](f, JSONCodec[int]{}, JSONCodec[string]{}))
+}
+
+func openJournal(t *testing.T, path string) *os.File {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = f.Close() })
+	return f
+}
+
+func TestTreeJournalReplay(t *testing.T) {
+	a := assert.New(t)
+	path := filepath.Join(t.TempDir(), "journal")
+	tree := newJournaledTree(openJournal(t, path))
+	for i := 0; i < 20; i++ {
+		tree.Insert(i, "v")
+	}
+	tree.Insert(5, "updated")
+	tree.Delete(0)
+	tree.Delete(100)
+	tree.DeleteAt(0)
+	it := tree.DeleteIterator(tree.LowerBound(10))
+	a.Equal(11, it.loc.key())
+	tree.UpdateKey(19, 50)
+	tree.UpdateKey(18, 17)
+	a.NoError(tree.JournalErr())
+
+	restored := newJournaledTree(openJournal(t, path))
+	a.NoError(restored.Replay(restored.journal.w.(*os.File)))
+	assertTreeEqual(t, tree, restored)
+
+	// new records must be appended after the replayed ones.
+	restored.Insert(-1, "new")
+	again := newJournaledTree(openJournal(t, path))
+	a.NoError(again.Replay(again.journal.w.(*os.File)))
+	assertTreeEqual(t, restored, again)
+}
+
+func TestTreeJournalTornRecord(t *testing.T) {
+	a := assert.New(t)
+	path := filepath.Join(t.TempDir(), "journal")
+	tree := newJournaledTree(openJournal(t, path))
+	tree.Insert(1, "a")
+	tree.Insert(2, "b")
+	info, err := os.Stat(path)
+	a.NoError(err)
+	tree.Insert(3, "c")
+
+	// simulate a crash in the middle of writing the last record.
+	for _, cut := range []int64{1, 3, 6} {
+		data, err := os.ReadFile(path)
+		a.NoError(err)
+		a.NoError(os.WriteFile(path, data[:len(data)-int(cut)], 0o600))
+
+		f := openJournal(t, path)
+		restored := newJournaledTree(f)
+		a.NoError(restored.Replay(f))
+		a.Equal(2, restored.Len())
+		_, found := restored.Find(3)
+		a.False(found)
+
+		size, err := f.Seek(0, 1)
+		a.NoError(err)
+		a.Equal(info.Size(), size)
+		restored.Insert(3, "c")
+		a.NoError(f.Sync())
+	}
+}
+
+func TestTreeJournalCorrupt(t *testing.T) {
+	a := assert.New(t)
+	var buf bytes.Buffer
+	tree := NewComparable[int, int](WithJournal[int, int](&buf, JSONCodec[int]{}, JSONCodec[int]{}))
+	tree.Insert(1, 1)
+	tree.Insert(2, 2)
+	data := buf.Bytes()
+	data[len(data)/4] ^= 0xff
+
+	restored := NewComparable[int, int](WithJournal[int, int](&bytes.Buffer{}, JSONCodec[int]{}, JSONCodec[int]{}))
+	err := restored.Replay(bytes.NewReader(data))
+	a.True(errors.Is(err, ErrJournalCorrupt))
+
+	a.True(errors.Is(NewComparable[int, int]().Replay(bytes.NewReader(data)), ErrNoJournal))
+}
+
+func TestTreeJournalCheckpoint(t *testing.T) {
+	a := assert.New(t)
+	path := filepath.Join(t.TempDir(), "journal")
+	tree := newJournaledTree(openJournal(t, path))
+	for i := 0; i < 100; i++ {
+		tree.Insert(i, "v")
+	}
+	for i := 0; i < 90; i++ {
+		tree.Delete(i)
+	}
+	before, err := os.Stat(path)
+	a.NoError(err)
+	a.NoError(tree.Checkpoint())
+	t.Cleanup(func() { _ = tree.JournalWriter().(*os.File).Close() })
+	after, err := os.Stat(path)
+	a.NoError(err)
+	a.Less(after.Size(), before.Size())
+	a.Equal(before.Mode(), after.Mode())
+	entries, err := os.ReadDir(filepath.Dir(path))
+	a.NoError(err)
+	a.Len(entries, 1)
+
+	tree.Clear()
+	tree.Insert(1000, "x")
+
+	restored := newJournaledTree(openJournal(t, path))
+	a.NoError(restored.Replay(restored.journal.w.(*os.File)))
+	assertTreeEqual(t, tree, restored)
+
+	a.True(errors.Is(NewComparable[int, int]().Checkpoint(), ErrNoJournal))
+	buffered := NewComparable[int, int](WithJournal[int, int](&bytes.Buffer{}, JSONCodec[int]{}, JSONCodec[int]{}))
+	a.True(errors.Is(buffered.Checkpoint(), ErrJournalNotTruncatable))
+}
+
+// failingCodec fails to encode the value bad.
+type failingCodec struct {
+	JSONCodec[string]
+	bad string
+}
+
+func (c failingCodec) Encode(v string) ([]byte, error) {
+	if v == c.bad {
+		return nil, errors.New("can't encode")
+	}
+	return c.JSONCodec.Encode(v)
+}
+
+func TestTreeJournalCheckpointFailure(t *testing.T) {
+	a := assert.New(t)
+	path := filepath.Join(t.TempDir(), "journal")
+	f := openJournal(t, path)
+	tree := NewComparable[int, string](WithJournal[int, string](f, JSONCodec[int]{}, failingCodec{bad: "bad"}))
+	tree.Insert(1, "a")
+	tree.Insert(2, "b")
+	tree.Delete(2)
+	// the value is encoded only by the snapshot.
+	v, _ := tree.Find(1)
+	*v = "bad"
+	before, err := os.ReadFile(path)
+	a.NoError(err)
+
+	a.Error(tree.Checkpoint())
+	a.NoError(tree.JournalErr())
+	a.Equal(f, tree.JournalWriter())
+	after, err := os.ReadFile(path)
+	a.NoError(err)
+	a.Equal(before, after)
+	entries, err := os.ReadDir(filepath.Dir(path))
+	a.NoError(err)
+	a.Len(entries, 1)
+
+	// the journal is still usable.
+	tree.Insert(3, "c")
+	restored := NewComparable[int, string](WithJournal[int, string](&bytes.Buffer{}, JSONCodec[int]{}, JSONCodec[string]{}))
+	a.NoError(restored.Replay(bytes.NewReader(readFile(t, path))))
+	a.Equal(2, restored.Len())
+	_, found := restored.Find(3)
+	a.True(found)
+}
+
+func readFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestTreeJournalZeroTail(t *testing.T) {
+	a := assert.New(t)
+	path := filepath.Join(t.TempDir(), "journal")
+	tree := newJournaledTree(openJournal(t, path))
+	tree.Insert(1, "a")
+	tree.Insert(2, "b")
+	valid := readFile(t, path)
+	tree.Insert(3, "c")
+	full := readFile(t, path)
+
+	// a crash can leave the end of the file zero-filled instead of the last record,
+	// or after a part of it.
+	for _, data := range [][]byte{
+		append(append([]byte{}, valid...), make([]byte, 100)...),
+		append(append([]byte{}, full[:len(valid)+3]...), make([]byte, 100)...),
+	} {
+		a.NoError(os.WriteFile(path, data, 0o600))
+		f := openJournal(t, path)
+		restored := newJournaledTree(f)
+		a.NoError(restored.Replay(f))
+		a.Equal(2, restored.Len())
+		size, err := f.Seek(0, 1)
+		a.NoError(err)
+		a.Equal(int64(len(valid)), size)
+	}
+
+	data := append(append([]byte{}, valid...), make([]byte, 100)...)
+	data = append(data, full[len(valid):]...)
+	restored := newJournaledTree(nil)
+	a.True(errors.Is(restored.Replay(bytes.NewReader(data)), ErrJournalCorrupt))
+}
+
+func TestTreeJournalGobDecode(t *testing.T) {
+	a := assert.New(t)
+	src := NewComparable[int, string]()
+	for i := 0; i < 10; i++ {
+		src.Insert(i, "v")
+	}
+	data, err := src.GobEncode()
+	a.NoError(err)
+
+	var buf bytes.Buffer
+	codecs := WithJournal[int, string](&buf, JSONCodec[int]{}, JSONCodec[string]{})
+	dst := NewComparable[int, string](codecs)
+	dst.Insert(100, "stale")
+	a.NoError(dst.GobDecode(data))
+
+	restored := NewComparable[int, string](WithJournal[int, string](&bytes.Buffer{}, JSONCodec[int]{}, JSONCodec[string]{}))
+	a.NoError(restored.Replay(&buf))
+	assertTreeEqual(t, src, restored)
+}
@@ -0,0 +1,197 @@
+package goavl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// JSONFormat defines how a tree is represented in JSON.
+type JSONFormat int8
+
+const (
+	// JSONArray encodes a tree as an ordered array of {"key": k, "value": v} objects.
+	// It works for any key type.
+	JSONArray JSONFormat = iota
+	// JSONObject encodes a tree as a JSON object, preserving the order of the keys.
+	// The keys must be encoded as JSON strings, i.e. be strings or implement encoding.TextMarshaler.
+	JSONObject
+)
+
+// ErrJSONKeyNotString is returned when a tree is encoded as a JSON object,
+// but its keys are not encoded as JSON strings.
+var ErrJSONKeyNotString = errors.New("goavl: json object keys must be strings")
+
+// WithJSONFormat sets the format used by MarshalJSON and WriteJSON.
+// The default is JSONArray.
+// Decoding accepts both formats regardless of this option.
+func WithJSONFormat(f JSONFormat) Option {
+	return func(o *Options) {
+		o.jsonFormat = f
+	}
+}
+
+type jsonEntry[K, V any] struct {
+	Key   K `json:"key"`
+	Value V `json:"value"`
+}
+
+// MarshalJSON implements json.Marshaler.
+// The entries are written in ascending order without building an intermediate slice.
+// Time complexity: O(n).
+func (t *Tree[K, V, Cmp]) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := t.writeJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteJSON streams the tree to w in the format set by WithJSONFormat.
+// Time complexity: O(n).
+func (t *Tree[K, V, Cmp]) WriteJSON(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if err := t.writeJSON(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func (t *Tree[K, V, Cmp]) writeJSON(w io.Writer) error {
+	open, closing := []byte{'['}, []byte{']'}
+	if t.options.jsonFormat == JSONObject {
+		open, closing = []byte{'{'}, []byte{'}'}
+	}
+	if _, err := w.Write(open); err != nil {
+		return err
+	}
+	it := t.IteratorAtFirst()
+	for i := 0; ; i++ {
+		e, ok := it.Next()
+		if !ok {
+			break
+		}
+		if i > 0 {
+			if _, err := w.Write([]byte{','}); err != nil {
+				return err
+			}
+		}
+		if err := t.writeJSONEntry(w, e); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(closing)
+	return err
+}
+
+func (t *Tree[K, V, Cmp]) writeJSONEntry(w io.Writer, e Entry[K, V]) error {
+	if t.options.jsonFormat != JSONObject {
+		data, err := json.Marshal(jsonEntry[K, V]{Key: e.Key, Value: *e.Value})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	key, err := json.Marshal(e.Key)
+	if err != nil {
+		return err
+	}
+	if len(key) == 0 || key[0] != '"' {
+		return fmt.Errorf("%w: got %s", ErrJSONKeyNotString, key)
+	}
+	value, err := json.Marshal(e.Value)
+	if err != nil {
+		return err
+	}
+	for _, part := range [...][]byte{key, {':'}, value} {
+		if _, err := w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// The tree must be created with New or NewComparable, as the comparator is not serialized,
+// otherwise ErrNoComparator is returned.
+// Current contents of the tree are replaced only if the whole value is decoded successfully.
+// Both JSONArray and JSONObject formats are accepted.
+// Time complexity: O(nlogn).
+func (t *Tree[K, V, Cmp]) UnmarshalJSON(data []byte) error {
+	return t.ReadJSON(bytes.NewReader(data))
+}
+
+// ReadJSON reads a single JSON value from r and replaces the contents of the tree.
+// Entries are decoded one by one into a new tree without building an intermediate slice,
+// which replaces the contents of the tree only if the whole value is decoded successfully.
+// Returns ErrNoComparator, if the tree was not created with New or NewComparable,
+// or ErrCapacityExceeded, if the number of entries exceeds the capacity, like GobDecode does.
+// Both JSONArray and JSONObject formats are accepted.
+// Time complexity: O(nlogn).
+func (t *Tree[K, V, Cmp]) ReadJSON(r io.Reader) error {
+	if t.cmp == nil {
+		return ErrNoComparator
+	}
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		t.Clear()
+		return nil
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok || (delim != '[' && delim != '{') {
+		return fmt.Errorf("goavl: unexpected json token %v", tok)
+	}
+	s := t.newScratch()
+	for dec.More() {
+		if delim == '[' {
+			var e jsonEntry[K, V]
+			if err := dec.Decode(&e); err != nil {
+				return err
+			}
+			s.Insert(e.Key, e.Value)
+			continue
+		}
+		k, err := decodeJSONObjectKey[K](dec)
+		if err != nil {
+			return err
+		}
+		var v V
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		s.Insert(k, v)
+	}
+	if _, err = dec.Token(); err != nil {
+		return err
+	}
+	if err := t.checkBulkLen(s.Len()); err != nil {
+		return err
+	}
+	t.replaceWith(s)
+	return nil
+}
+
+func decodeJSONObjectKey[K any](dec *json.Decoder) (k K, err error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return k, err
+	}
+	s, ok := tok.(string)
+	if !ok {
+		return k, fmt.Errorf("goavl: unexpected json token %v", tok)
+	}
+	quoted, err := json.Marshal(s)
+	if err != nil {
+		return k, err
+	}
+	err = json.Unmarshal(quoted, &k)
+	return k, err
+}
@@ -0,0 +1,169 @@
+package goavl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeJSONArray(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, string]()
+	for _, k := range []int{3, 1, 2} {
+		tree.Insert(k, string(rune('a'+k)))
+	}
+	data, err := json.Marshal(tree)
+	a.NoError(err)
+	a.Equal(`[{"key":1,"value":"b"},{"key":2,"value":"c"},{"key":3,"value":"d"}]`, string(data))
+
+	decoded := NewComparable[int, string](WithCountChildren(true))
+	decoded.Insert(100, "stale")
+	a.NoError(json.Unmarshal(data, decoded))
+	a.Equal(3, decoded.Len())
+	for i, want := range []string{"b", "c", "d"} {
+		e := decoded.At(i)
+		a.Equal(i+1, e.Key)
+		a.Equal(want, *e.Value)
+	}
+	a.NoError(checkHeightAndBalance(decoded.root, decoded.options.countChildren))
+}
+
+func TestTreeJSONObject(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[string, int](WithJSONFormat(JSONObject))
+	for i, k := range []string{"z", "a", "m"} {
+		tree.Insert(k, i)
+	}
+	data, err := json.Marshal(tree)
+	a.NoError(err)
+	a.Equal(`{"a":1,"m":2,"z":0}`, string(data))
+
+	var buf bytes.Buffer
+	a.NoError(tree.WriteJSON(&buf))
+	a.Equal(string(data), buf.String())
+
+	decoded := NewComparable[string, int]()
+	a.NoError(decoded.ReadJSON(&buf))
+	assertTreeEqual(t, tree, decoded)
+}
+
+func TestTreeJSONObjectNonStringKeys(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int](WithJSONFormat(JSONObject))
+	tree.Insert(1, 1)
+	_, err := json.Marshal(tree)
+	a.True(errors.Is(err, ErrJSONKeyNotString))
+}
+
+func TestTreeJSONEmptyAndNull(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int]()
+	data, err := json.Marshal(tree)
+	a.NoError(err)
+	a.Equal(`[]`, string(data))
+
+	tree.Insert(1, 1)
+	a.NoError(json.Unmarshal([]byte(`null`), tree))
+	a.Zero(tree.Len())
+
+	a.Error(json.Unmarshal([]byte(`"str"`), tree))
+	a.Error(json.Unmarshal([]byte(`[{"key":"x"}]`), tree))
+}
+
+func TestTreeJSONNoComparator(t *testing.T) {
+	a := assert.New(t)
+	var tree Tree[int, int, func(a, b int) int]
+	a.ErrorIs(json.Unmarshal([]byte(`[{"key":1,"value":1}]`), &tree), ErrNoComparator)
+	var s struct {
+		T *Tree[int, int, func(a, b int) int]
+	}
+	a.ErrorIs(json.Unmarshal([]byte(`{"T":[{"key":1,"value":1}]}`), &s), ErrNoComparator)
+}
+
+func TestTreeJSONDecodeError(t *testing.T) {
+	a := assert.New(t)
+	var inserts int
+	tree := NewComparable[int, int](withTestWeights(), WithObserver[int, int](ObserverFuncs[int, int]{
+		Insert: func(int, int) { inserts++ },
+	}))
+	tree.Insert(5, 50)
+	inserts = 0
+	for _, s := range []string{
+		`[{"key":1,"value":1},{"key":2,"value":"x"}]`,
+		`[{"key":1,"value":1},{"key":2,"value":2}`,
+		`{"1":1,"2":`,
+	} {
+		a.Error(tree.ReadJSON(bytes.NewReader([]byte(s))), s)
+		a.Equal(1, tree.Len())
+		v, found := tree.Find(5)
+		a.True(found)
+		a.Equal(50, *v)
+		a.Zero(inserts)
+	}
+	a.NoError(tree.ReadJSON(bytes.NewReader([]byte(`[{"key":1,"value":1},{"key":2,"value":2}]`))))
+	a.Equal(2, inserts)
+	if weightsEnabled {
+		a.Equal(uint64(3), tree.TotalWeight())
+	}
+	a.NoError(tree.Validate())
+}
+
+func TestTreeJSONCapacity(t *testing.T) {
+	a := assert.New(t)
+	src := NewComparable[int, int]()
+	for i := 0; i < 3; i++ {
+		src.Insert(i, i)
+	}
+	data, err := json.Marshal(src)
+	a.NoError(err)
+	gobData, err := src.GobEncode()
+	a.NoError(err)
+
+	tree := NewComparable[int, int](WithCapacity(2, EvictMin))
+	tree.Insert(10, 10)
+	a.ErrorIs(json.Unmarshal(data, tree), ErrCapacityExceeded)
+	a.ErrorIs(tree.GobDecode(gobData), ErrCapacityExceeded)
+	a.Equal(1, tree.Len())
+
+	tree = NewComparable[int, int](WithCapacity(3, EvictMin))
+	a.NoError(json.Unmarshal(data, tree))
+	assertTreeEqual(t, src, tree)
+}
+
+func TestTreeJSONNested(t *testing.T) {
+	a := assert.New(t)
+	type doc struct {
+		Tree *Tree[string, int, func(a, b string) int] `json:"tree"`
+	}
+	in := doc{Tree: NewComparable[string, int](WithJSONFormat(JSONObject))}
+	in.Tree.Insert("b", 2)
+	in.Tree.Insert("a", 1)
+	data, err := json.Marshal(in)
+	a.NoError(err)
+	a.Equal(`{"tree":{"a":1,"b":2}}`, string(data))
+
+	out := doc{Tree: NewComparable[string, int]()}
+	a.NoError(json.Unmarshal(data, &out))
+	assertTreeEqual(t, in.Tree, out.Tree)
+}
+
+func assertTreeEqual[K, V any, Cmp func(a, b K) int](t *testing.T, want, got *Tree[K, V, Cmp]) {
+	t.Helper()
+	a := assert.New(t)
+	a.Equal(want.Len(), got.Len())
+	wantIt, gotIt := want.IteratorAtFirst(), got.IteratorAtFirst()
+	for {
+		we, wok := wantIt.Next()
+		ge, gok := gotIt.Next()
+		a.Equal(wok, gok)
+		if !wok || !gok {
+			break
+		}
+		a.Equal(we.Key, ge.Key)
+		a.Equal(*we.Value, *ge.Value)
+	}
+	a.NoError(checkHeightAndBalance(got.root, got.options.countChildren))
+}
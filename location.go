@@ -162,7 +162,7 @@ func (l *location[K, V]) recalcHeight() (heightChanged bool) {
 }
 
 func (l *location[K, V]) recalcCounts() {
-	var nchild uint32
+	var nchild childCount
 	if left := l.left(); !left.isNil() {
 		nchild += 1 + left.childrenCount()
 	}
@@ -184,7 +184,7 @@ func (l *location[K, V]) left() location[K, V] {
 	return l.ptrNode.left
 }
 
-func (l *location[K, V]) leftChildrenCount() uint32 {
+func (l *location[K, V]) leftChildrenCount() childCount {
 	if l := l.left(); !l.isNil() {
 		return 1 + l.childrenCount()
 	}
@@ -0,0 +1,193 @@
+package goavl
+
+const (
+	mergeKeepAll = iota
+	mergeFirstWins
+	mergeLastWins
+	mergeCombine
+)
+
+// MergeOption configures a MergeIterator, see MergeIterator.With.
+type MergeOption[K, V any] func(o *mergeOptions[K, V])
+
+type mergeOptions[K, V any] struct {
+	duplicates int8
+	combine    func(k K, a, b V) V
+	lo, hi     *K
+}
+
+// MergeFirstWins makes a MergeIterator return only the value from the first tree containing a key.
+func MergeFirstWins[K, V any]() MergeOption[K, V] {
+	return func(o *mergeOptions[K, V]) {
+		o.duplicates = mergeFirstWins
+	}
+}
+
+// MergeLastWins makes a MergeIterator return only the value from the last tree containing a key.
+func MergeLastWins[K, V any]() MergeOption[K, V] {
+	return func(o *mergeOptions[K, V]) {
+		o.duplicates = mergeLastWins
+	}
+}
+
+// MergeCombine makes a MergeIterator return a single value for a key present in several trees.
+// The values are folded in the order of the trees: combine(k, combine(k, v1, v2), v3).
+func MergeCombine[K, V any](combine func(k K, a, b V) V) MergeOption[K, V] {
+	return func(o *mergeOptions[K, V]) {
+		o.duplicates = mergeCombine
+		o.combine = combine
+	}
+}
+
+// MergeFrom makes a MergeIterator start from the first key that is not less than lo.
+func MergeFrom[K, V any](lo K) MergeOption[K, V] {
+	return func(o *mergeOptions[K, V]) {
+		o.lo = &lo
+	}
+}
+
+// MergeTo makes a MergeIterator stop after the last key that is not greater than hi.
+func MergeTo[K, V any](hi K) MergeOption[K, V] {
+	return func(o *mergeOptions[K, V]) {
+		o.hi = &hi
+	}
+}
+
+type mergeSource[K, V any, Cmp func(a, b K) int] struct {
+	it    Iterator[K, V, Cmp]
+	entry Entry[K, V]
+	index int
+}
+
+// MergeIterator iterates over several trees in ascending key order.
+// By default, if a key is present in several trees, it's returned once for every tree,
+// in the order of the trees. Use MergeFirstWins, MergeLastWins or MergeCombine to change this.
+// The trees must not be modified during the iteration.
+type MergeIterator[K, V any, Cmp func(a, b K) int] struct {
+	trees   []*Tree[K, V, Cmp]
+	started bool
+	heap    []mergeSource[K, V, Cmp]
+	cmp     Cmp
+	opts    mergeOptions[K, V]
+}
+
+// NewMergeIterator returns an iterator merging the given trees.
+// The comparator of the first tree is used to order keys, all the trees must be ordered the same way.
+// Use With to set the options.
+// Time complexity: O(mlogm) to start and O(logm) per entry, where m is the number of trees,
+// plus O(logn) per tree if MergeFrom is used.
+func NewMergeIterator[K, V any, Cmp func(a, b K) int](trees ...*Tree[K, V, Cmp]) *MergeIterator[K, V, Cmp] {
+	return &MergeIterator[K, V, Cmp]{trees: trees}
+}
+
+// With applies the options to m and returns it.
+// Panics if the iteration has already started.
+//
+// Example:
+//
+//	m := NewMergeIterator(t1, t2, t3).With(MergeFirstWins[int, string](), MergeFrom[int, string](10)).
+func (m *MergeIterator[K, V, Cmp]) With(opts ...MergeOption[K, V]) *MergeIterator[K, V, Cmp] {
+	if m.started {
+		panic("goavl: MergeIterator options must be set before the iteration")
+	}
+	for _, o := range opts {
+		o(&m.opts)
+	}
+	return m
+}
+
+// start positions the iterators of the trees and builds the heap.
+func (m *MergeIterator[K, V, Cmp]) start() {
+	m.started = true
+	m.heap = make([]mergeSource[K, V, Cmp], 0, len(m.trees))
+	for i, t := range m.trees {
+		if i == 0 {
+			m.cmp = t.cmp
+		}
+		it := t.IteratorAtFirst()
+		if m.opts.lo != nil {
+			it = t.LowerBound(*m.opts.lo)
+		}
+		src := mergeSource[K, V, Cmp]{it: it, index: i}
+		if m.advance(&src) {
+			m.heap = append(m.heap, src)
+		}
+	}
+	for i := len(m.heap)/2 - 1; i >= 0; i-- {
+		m.down(i)
+	}
+	m.trees = nil
+}
+
+// Next returns the next entry.
+// found is false when there are no more entries.
+func (m *MergeIterator[K, V, Cmp]) Next() (k K, v V, found bool) {
+	if !m.started {
+		m.start()
+	}
+	if len(m.heap) == 0 {
+		return k, v, false
+	}
+	k, v = m.heap[0].entry.Key, *m.heap[0].entry.Value
+	m.pop()
+	if m.opts.duplicates == mergeKeepAll {
+		return k, v, true
+	}
+	for len(m.heap) > 0 && m.cmp(m.heap[0].entry.Key, k) == 0 {
+		switch next := *m.heap[0].entry.Value; m.opts.duplicates {
+		case mergeLastWins:
+			v = next
+		case mergeCombine:
+			v = m.opts.combine(k, v, next)
+		}
+		m.pop()
+	}
+	return k, v, true
+}
+
+// advance moves src to its next entry. Returns false if src is exhausted.
+func (m *MergeIterator[K, V, Cmp]) advance(src *mergeSource[K, V, Cmp]) bool {
+	e, ok := src.it.Next()
+	if !ok || (m.opts.hi != nil && m.cmp(e.Key, *m.opts.hi) > 0) {
+		return false
+	}
+	src.entry = e
+	return true
+}
+
+// pop advances the top source and restores the heap.
+func (m *MergeIterator[K, V, Cmp]) pop() {
+	if !m.advance(&m.heap[0]) {
+		last := len(m.heap) - 1
+		m.heap[0] = m.heap[last]
+		m.heap = m.heap[:last]
+	}
+	if len(m.heap) > 0 {
+		m.down(0)
+	}
+}
+
+func (m *MergeIterator[K, V, Cmp]) less(i, j int) bool {
+	a, b := &m.heap[i], &m.heap[j]
+	if c := m.cmp(a.entry.Key, b.entry.Key); c != 0 {
+		return c < 0
+	}
+	return a.index < b.index
+}
+
+func (m *MergeIterator[K, V, Cmp]) down(i int) {
+	for {
+		smallest := i
+		if l := 2*i + 1; l < len(m.heap) && m.less(l, smallest) {
+			smallest = l
+		}
+		if r := 2*i + 2; r < len(m.heap) && m.less(r, smallest) {
+			smallest = r
+		}
+		if smallest == i {
+			return
+		}
+		m.heap[i], m.heap[smallest] = m.heap[smallest], m.heap[i]
+		i = smallest
+	}
+}
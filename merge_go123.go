@@ -0,0 +1,27 @@
+//go:build go1.23
+
+package goavl
+
+import "iter"
+
+// MergeIter returns an iterator over the entries of several trees in ascending key order.
+// It can be used in a for-range loop (Go 1.23+). See NewMergeIterator for the details.
+// Use NewMergeIterator(trees...).With(opts...).All() to set the options.
+func MergeIter[K, V any, Cmp func(a, b K) int](trees ...*Tree[K, V, Cmp]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		NewMergeIterator(trees...).All()(yield)
+	}
+}
+
+// All returns an iterator over the remaining entries of m.
+// It can be used in a for-range loop (Go 1.23+). m can be iterated only once.
+func (m *MergeIterator[K, V, Cmp]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for {
+			k, v, ok := m.Next()
+			if !ok || !yield(k, v) {
+				break
+			}
+		}
+	}
+}
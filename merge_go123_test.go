@@ -0,0 +1,31 @@
+//go:build go1.23
+
+package goavl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeIterGo123(t *testing.T) {
+	a := assert.New(t)
+	trees := newMergeTrees()
+	var keys []int
+	for k := range NewMergeIterator(trees...).With(MergeLastWins[int, string]()).All() {
+		keys = append(keys, k)
+		if k == 7 {
+			break
+		}
+	}
+	a.Equal([]int{1, 2, 4, 7}, keys)
+
+	keys = nil
+	merged := MergeIter(trees...)
+	for i := 0; i < 2; i++ {
+		for k := range merged {
+			keys = append(keys, k)
+		}
+	}
+	a.Equal([]int{1, 2, 4, 4, 4, 7, 8, 8, 9, 1, 2, 4, 4, 4, 7, 8, 8, 9}, keys)
+}
@@ -0,0 +1,96 @@
+package goavl
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mergeTree = Tree[int, string, func(a, b int) int]
+
+func newMergeTrees() []*mergeTree {
+	t1 := NewComparable[int, string]()
+	t2 := NewComparable[int, string]()
+	t3 := NewComparable[int, string]()
+	for _, k := range []int{1, 4, 7} {
+		t1.Insert(k, "a")
+	}
+	for _, k := range []int{2, 4, 8} {
+		t2.Insert(k, "b")
+	}
+	for _, k := range []int{4, 8, 9} {
+		t3.Insert(k, "c")
+	}
+	return []*mergeTree{t1, NewComparable[int, string](), t2, t3}
+}
+
+func collectMerge(m *MergeIterator[int, string, func(a, b int) int]) (keys []int, values []string) {
+	for {
+		k, v, ok := m.Next()
+		if !ok {
+			return keys, values
+		}
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+}
+
+func TestMergeIterator(t *testing.T) {
+	a := assert.New(t)
+	trees := newMergeTrees()
+
+	keys, values := collectMerge(NewMergeIterator(trees...))
+	a.Equal([]int{1, 2, 4, 4, 4, 7, 8, 8, 9}, keys)
+	a.Equal([]string{"a", "b", "a", "b", "c", "a", "b", "c", "c"}, values)
+
+	keys, values = collectMerge(NewMergeIterator(trees...).With(MergeFirstWins[int, string]()))
+	a.Equal([]int{1, 2, 4, 7, 8, 9}, keys)
+	a.Equal([]string{"a", "b", "a", "a", "b", "c"}, values)
+
+	keys, values = collectMerge(NewMergeIterator(trees...).With(MergeLastWins[int, string]()))
+	a.Equal([]int{1, 2, 4, 7, 8, 9}, keys)
+	a.Equal([]string{"a", "b", "c", "a", "c", "c"}, values)
+
+	concat := MergeCombine(func(k int, a, b string) string { return a + b })
+	keys, values = collectMerge(NewMergeIterator(trees...).With(concat))
+	a.Equal([]int{1, 2, 4, 7, 8, 9}, keys)
+	a.Equal([]string{"a", "b", "abc", "a", "bc", "c"}, values)
+
+	keys, _ = collectMerge(NewMergeIterator(trees...).With(MergeFrom[int, string](3), MergeTo[int, string](8), MergeFirstWins[int, string]()))
+	a.Equal([]int{4, 7, 8}, keys)
+
+	keys, _ = collectMerge(NewMergeIterator(trees...).With(MergeFrom[int, string](10)))
+	a.Empty(keys)
+	keys, _ = collectMerge(NewMergeIterator[int, string, func(a, b int) int]())
+	a.Empty(keys)
+
+	keys, _ = collectMerge(NewMergeIterator(trees[0], trees[2]).With(MergeFirstWins[int, string]()))
+	a.Equal([]int{1, 2, 4, 7, 8}, keys)
+	m := NewMergeIterator(trees...)
+	m.Next()
+	a.Panics(func() {
+		m.With(MergeLastWins[int, string]())
+	})
+}
+
+func TestMergeIteratorRandom(t *testing.T) {
+	a := assert.New(t)
+	r := rand.New(rand.NewSource(3))
+	var trees []*mergeTree
+	var all []int
+	for i := 0; i < 10; i++ {
+		tree := NewComparable[int, string]()
+		for j := 0; j < 100; j++ {
+			k := r.Intn(1000)
+			if _, inserted := tree.Insert(k, ""); inserted {
+				all = append(all, k)
+			}
+		}
+		trees = append(trees, tree)
+	}
+	sort.Ints(all)
+	keys, _ := collectMerge(NewMergeIterator(trees...))
+	a.Equal(all, keys)
+}
@@ -0,0 +1,46 @@
+package goavl
+
+import "math"
+
+// Nearest returns the element with the key closest to k according to dist.
+// If two keys are at the same distance, the lesser one is returned.
+// dist(k, x) must not decrease as x moves away from k in key order, for instance, |k - x| for numbers.
+// Returns false if the tree is empty.
+// Time complexity: O(logn).
+func (t *Tree[K, V, Cmp]) Nearest(k K, dist func(a, b K) float64) (entry Entry[K, V], found bool) {
+	t.NearestEach(k, 1, math.Inf(1), dist, func(k K, v *V) bool {
+		entry, found = Entry[K, V]{Key: k, Value: v}, true
+		return false
+	})
+	return entry, found
+}
+
+// NearestEach calls f for at most n elements with keys closest to k in the order of increasing distance,
+// until f returns false. Only the elements within distance r from k are visited.
+// Use n < 0 for no limit on the number of elements, and math.Inf(1) for no limit on the distance.
+// The search starts at Floor(k) and LowerBound(k) and expands in both directions.
+// If two keys are at the same distance, the lesser one goes first.
+// dist(k, x) must not decrease as x moves away from k in key order, for instance, |k - x| for numbers.
+// The tree must not be modified during the iteration.
+// Time complexity: O(logn + m), where m is the number of visited elements.
+func (t *Tree[K, V, Cmp]) NearestEach(k K, n int, r float64, dist func(a, b K) float64, f func(k K, v *V) bool) {
+	lo := t.Floor(k).loc
+	hi := t.min
+	if !lo.isNil() {
+		hi = nextLocation(lo)
+	}
+	for ; n != 0 && (!lo.isNil() || !hi.isNil()); n-- {
+		var loc location[K, V]
+		var d float64
+		if lo.isNil() || (!hi.isNil() && dist(k, hi.key()) < dist(k, lo.key())) {
+			loc, d = hi, dist(k, hi.key())
+			hi = nextLocation(hi)
+		} else {
+			loc, d = lo, dist(k, lo.key())
+			lo = prevLocation(lo)
+		}
+		if d > r || !f(loc.key(), loc.valuePtr()) {
+			return
+		}
+	}
+}
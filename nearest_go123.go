@@ -0,0 +1,28 @@
+//go:build go1.23
+
+package goavl
+
+import (
+	"iter"
+	"math"
+)
+
+// KNearest returns an iterator over at most n elements with keys closest to k in the order of increasing distance.
+// It can be used in a for-range loop (Go 1.23+). See NearestEach for the details.
+func (t *Tree[K, V, Cmp]) KNearest(k K, n int, dist func(a, b K) float64) iter.Seq2[K, V] {
+	return t.nearest(k, n, math.Inf(1), dist)
+}
+
+// Within returns an iterator over the elements with keys at most r away from k in the order of increasing distance.
+// It can be used in a for-range loop (Go 1.23+). See NearestEach for the details.
+func (t *Tree[K, V, Cmp]) Within(k K, r float64, dist func(a, b K) float64) iter.Seq2[K, V] {
+	return t.nearest(k, -1, r, dist)
+}
+
+func (t *Tree[K, V, Cmp]) nearest(k K, n int, r float64, dist func(a, b K) float64) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		t.NearestEach(k, n, r, dist, func(k K, v *V) bool {
+			return yield(k, *v)
+		})
+	}
+}
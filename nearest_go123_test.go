@@ -0,0 +1,38 @@
+//go:build go1.23
+
+package goavl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeKNearestWithin(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int]()
+	for _, k := range []int{10, 20, 30, 40} {
+		tree.Insert(k, k*10)
+	}
+	var keys, values []int
+	for k, v := range tree.KNearest(33, 3, intDist) {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	a.Equal([]int{30, 40, 20}, keys)
+	a.Equal([]int{300, 400, 200}, values)
+
+	keys = nil
+	for k := range tree.Within(12, 10, intDist) {
+		keys = append(keys, k)
+	}
+	a.Equal([]int{10, 20}, keys)
+
+	keys = nil
+	for k := range tree.Within(25, 100, intDist) {
+		if keys = append(keys, k); len(keys) == 2 {
+			break
+		}
+	}
+	a.Equal([]int{20, 30}, keys)
+}
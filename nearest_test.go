@@ -0,0 +1,83 @@
+package goavl
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intDist(a, b int) float64 {
+	return math.Abs(float64(a - b))
+}
+
+func nearestKeys(tree *Tree[int, int, func(a, b int) int], k, n int, r float64) []int {
+	var result []int
+	tree.NearestEach(k, n, r, intDist, func(k int, _ *int) bool {
+		result = append(result, k)
+		return true
+	})
+	return result
+}
+
+func TestTreeNearest(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int]()
+	_, found := tree.Nearest(1, intDist)
+	a.False(found)
+	a.Nil(nearestKeys(tree, 1, -1, math.Inf(1)))
+
+	for _, k := range []int{10, 20, 30, 40} {
+		tree.Insert(k, k*10)
+	}
+	for k, want := range map[int]int{-5: 10, 10: 10, 14: 10, 15: 10, 16: 20, 20: 20, 36: 40, 100: 40} {
+		e, found := tree.Nearest(k, intDist)
+		a.True(found)
+		a.Equalf(want, e.Key, "k = %d", k)
+		a.Equal(want*10, *e.Value)
+	}
+
+	a.Equal([]int{20, 30, 10, 40}, nearestKeys(tree, 25, -1, math.Inf(1)))
+	a.Equal([]int{20, 30}, nearestKeys(tree, 25, 2, math.Inf(1)))
+	a.Equal([]int{30, 20, 40}, nearestKeys(tree, 29, -1, 11))
+	a.Equal([]int{40, 30}, nearestKeys(tree, 45, -1, 15))
+	a.Equal([]int{10}, nearestKeys(tree, 0, -1, 10))
+	a.Nil(nearestKeys(tree, 0, -1, 9))
+	a.Nil(nearestKeys(tree, 25, 0, math.Inf(1)))
+
+	var visited []int
+	tree.NearestEach(21, -1, math.Inf(1), intDist, func(k int, _ *int) bool {
+		visited = append(visited, k)
+		return len(visited) < 2
+	})
+	a.Equal([]int{20, 30}, visited)
+}
+
+func TestTreeNearestRandom(t *testing.T) {
+	a := assert.New(t)
+	r := rand.New(rand.NewSource(7))
+	tree := NewComparable[int, int]()
+	var keys []int
+	for i := 0; i < 300; i++ {
+		k := r.Intn(1000)
+		if _, inserted := tree.Insert(k, i); inserted {
+			keys = append(keys, k)
+		}
+	}
+	for i := 0; i < 100; i++ {
+		k := r.Intn(1100) - 50
+		radius := float64(r.Intn(100))
+		want := append([]int(nil), keys...)
+		sort.SliceStable(want, func(i, j int) bool {
+			di, dj := intDist(k, want[i]), intDist(k, want[j])
+			return di < dj || di == dj && want[i] < want[j]
+		})
+		n := sort.Search(len(want), func(i int) bool {
+			return intDist(k, want[i]) > radius
+		})
+		a.Equal(want[:n], append([]int{}, nearestKeys(tree, k, -1, radius)...))
+		a.Equal(want[:10], nearestKeys(tree, k, 10, math.Inf(1)))
+	}
+}
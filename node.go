@@ -1,10 +1,13 @@
 package goavl
 
+// node's h is the height of the subtree. An AVL tree with 2^64 nodes is lower than 93, so uint8 is enough.
+// nodeWeight goes first, so that it takes no space, if it's empty.
 type node[K, V any] struct {
+	nodeWeight
 	k      K
 	v      V
 	h      uint8
-	nchild uint32
+	nchild childCount
 }
 
 func (n *node[K, V]) height() uint8 {
@@ -15,11 +18,11 @@ func (n *node[K, V]) setHeight(height uint8) {
 	n.h = height
 }
 
-func (n *node[K, V]) childrenCount() uint32 {
+func (n *node[K, V]) childrenCount() childCount {
 	return n.nchild
 }
 
-func (n *node[K, V]) setChildrenCount(nchild uint32) {
+func (n *node[K, V]) setChildrenCount(nchild childCount) {
 	n.nchild = nchild
 }
 
@@ -40,4 +43,5 @@ func (n *node[K, V]) init(k K, v V) {
 	n.v = v
 	n.h = 0
 	n.nchild = 0
+	n.nodeWeight = nodeWeight{}
 }
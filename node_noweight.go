@@ -0,0 +1,16 @@
+//go:build !goavl_weights
+
+package goavl
+
+// weightsEnabled is true, if the package is built with the goavl_weights tag.
+const weightsEnabled = false
+
+// nodeWeight takes no space, unless the package is built with the goavl_weights tag.
+// Without the tag WithWeights panics.
+type nodeWeight struct{}
+
+func (w *nodeWeight) subtreeWeight() uint64 {
+	return 0
+}
+
+func (w *nodeWeight) setSubtreeWeight(uint64) {}
@@ -0,0 +1,20 @@
+//go:build goavl_weights
+
+package goavl
+
+// weightsEnabled is true, if the package is built with the goavl_weights tag.
+const weightsEnabled = true
+
+// nodeWeight keeps the total weight of a subtree, if WithWeights is used.
+// The goavl_weights build tag makes nodes 8 bytes larger.
+type nodeWeight struct {
+	wsum uint64
+}
+
+func (w *nodeWeight) subtreeWeight() uint64 {
+	return w.wsum
+}
+
+func (w *nodeWeight) setSubtreeWeight(wsum uint64) {
+	w.wsum = wsum
+}
@@ -0,0 +1,194 @@
+package goavl
+
+import "fmt"
+
+// Observer receives notifications about tree mutations.
+// The callbacks are called synchronously after the tree is modified,
+// so they must not modify the tree.
+type Observer[K, V any] interface {
+	// OnInsert is called when a new key is inserted.
+	OnInsert(k K, v V)
+	// OnUpdate is called when Insert replaces the value of an existing key.
+	OnUpdate(k K, oldValue, newValue V)
+	// OnDelete is called when a key is deleted by Delete, DeleteAt, DeleteIterator or Mutator.Delete.
+	// It is also called by UpdateKey for newKey, if its old value was replaced, and for every element
+	// removed by Clear, or replaced by decoding, like GobDecode and ReadJSON do.
+	OnDelete(k K, v V)
+	// OnKeyChange is called when UpdateKey moves a value from oldKey to newKey.
+	OnKeyChange(oldKey, newKey K, v V)
+}
+
+// ObserverFuncs is an Observer that calls the functions that are set.
+type ObserverFuncs[K, V any] struct {
+	Insert    func(k K, v V)
+	Update    func(k K, oldValue, newValue V)
+	Delete    func(k K, v V)
+	KeyChange func(oldKey, newKey K, v V)
+}
+
+// OnInsert calls f.Insert, if set.
+func (f ObserverFuncs[K, V]) OnInsert(k K, v V) {
+	if f.Insert != nil {
+		f.Insert(k, v)
+	}
+}
+
+// OnUpdate calls f.Update, if set.
+func (f ObserverFuncs[K, V]) OnUpdate(k K, oldValue, newValue V) {
+	if f.Update != nil {
+		f.Update(k, oldValue, newValue)
+	}
+}
+
+// OnDelete calls f.Delete, if set.
+func (f ObserverFuncs[K, V]) OnDelete(k K, v V) {
+	if f.Delete != nil {
+		f.Delete(k, v)
+	}
+}
+
+// OnKeyChange calls f.KeyChange, if set.
+func (f ObserverFuncs[K, V]) OnKeyChange(oldKey, newKey K, v V) {
+	if f.KeyChange != nil {
+		f.KeyChange(oldKey, newKey, v)
+	}
+}
+
+// WithObserver adds an observer that is notified about tree mutations.
+// The option can be used several times, observers are notified in the order they were added.
+// K and V must match the tree's type parameters.
+func WithObserver[K, V any](o Observer[K, V]) Option {
+	return func(opts *Options) {
+		opts.observers = append(opts.observers, o)
+	}
+}
+
+func newObservers[K, V any](opts []any) []Observer[K, V] {
+	if len(opts) == 0 {
+		return nil
+	}
+	result := make([]Observer[K, V], 0, len(opts))
+	for _, o := range opts {
+		obs, ok := o.(Observer[K, V])
+		if !ok {
+			panic(fmt.Sprintf("goavl: observer type %T doesn't match the tree", o))
+		}
+		result = append(result, obs)
+	}
+	return result
+}
+
+func (t *Tree[K, V, Cmp]) addObserver(o Observer[K, V]) {
+	t.observers = append(t.observers, o)
+}
+
+func (t *Tree[K, V, Cmp]) removeObserver(o Observer[K, V]) {
+	for i, obs := range t.observers {
+		if obs == o {
+			t.observers = append(t.observers[:i:i], t.observers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (t *Tree[K, V, Cmp]) notifyInsert(k K, v V) {
+	for _, o := range t.observers {
+		o.OnInsert(k, v)
+	}
+}
+
+func (t *Tree[K, V, Cmp]) notifyUpdate(k K, oldValue, newValue V) {
+	for _, o := range t.observers {
+		o.OnUpdate(k, oldValue, newValue)
+	}
+}
+
+func (t *Tree[K, V, Cmp]) notifyDelete(k K, v V) {
+	for _, o := range t.observers {
+		o.OnDelete(k, v)
+	}
+}
+
+func (t *Tree[K, V, Cmp]) notifyKeyChange(oldKey, newKey K, v V) {
+	for _, o := range t.observers {
+		o.OnKeyChange(oldKey, newKey, v)
+	}
+}
+
+// EventKind is the type of a mutation event.
+type EventKind int8
+
+const (
+	// EventInsert is sent when a new key is inserted.
+	EventInsert EventKind = iota + 1
+	// EventUpdate is sent when the value of an existing key is replaced by Insert.
+	EventUpdate
+	// EventDelete is sent when a key is deleted.
+	EventDelete
+	// EventKeyChange is sent when UpdateKey moves a value from OldKey to Key.
+	EventKeyChange
+)
+
+// Event describes a single mutation of a tree.
+type Event[K, V any] struct {
+	Kind EventKind
+	Key  K
+	// OldKey is set for EventKeyChange.
+	OldKey K
+	Value  V
+	// OldValue is set for EventUpdate.
+	OldValue V
+}
+
+type watcher[K, V any, Cmp func(a, b K) int] struct {
+	lo, hi K
+	cmp    Cmp
+	ch     chan Event[K, V]
+}
+
+func (w *watcher[K, V, Cmp]) inRange(k K) bool {
+	return w.cmp(w.lo, k) <= 0 && w.cmp(k, w.hi) <= 0
+}
+
+func (w *watcher[K, V, Cmp]) OnInsert(k K, v V) {
+	if w.inRange(k) {
+		w.ch <- Event[K, V]{Kind: EventInsert, Key: k, Value: v}
+	}
+}
+
+func (w *watcher[K, V, Cmp]) OnUpdate(k K, oldValue, newValue V) {
+	if w.inRange(k) {
+		w.ch <- Event[K, V]{Kind: EventUpdate, Key: k, Value: newValue, OldValue: oldValue}
+	}
+}
+
+func (w *watcher[K, V, Cmp]) OnDelete(k K, v V) {
+	if w.inRange(k) {
+		w.ch <- Event[K, V]{Kind: EventDelete, Key: k, Value: v}
+	}
+}
+
+func (w *watcher[K, V, Cmp]) OnKeyChange(oldKey, newKey K, v V) {
+	if w.inRange(oldKey) || w.inRange(newKey) {
+		w.ch <- Event[K, V]{Kind: EventKeyChange, Key: newKey, OldKey: oldKey, Value: v}
+	}
+}
+
+// Watch returns a channel that receives events for the keys in the inclusive range [lo, hi].
+// For EventKeyChange it's enough for either of the keys to be in the range.
+// The channel has a buffer of size buf. When the buffer is full, mutations of the tree block
+// until the events are received, so the channel must be drained by another goroutine.
+// Calling stop unregisters the watcher and closes the channel.
+// Like other mutations, stop must not be called concurrently with the tree's methods.
+func (t *Tree[K, V, Cmp]) Watch(lo, hi K, buf int) (events <-chan Event[K, V], stop func()) {
+	w := &watcher[K, V, Cmp]{lo: lo, hi: hi, cmp: t.cmp, ch: make(chan Event[K, V], buf)}
+	t.addObserver(w)
+	var stopped bool
+	return w.ch, func() {
+		if !stopped {
+			stopped = true
+			t.removeObserver(w)
+			close(w.ch)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package goavl
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingObserver struct {
+	events []string
+}
+
+func (r *recordingObserver) OnInsert(k int, v string) {
+	r.events = append(r.events, fmt.Sprintf("insert %d=%s", k, v))
+}
+
+func (r *recordingObserver) OnUpdate(k int, oldValue, newValue string) {
+	r.events = append(r.events, fmt.Sprintf("update %d=%s->%s", k, oldValue, newValue))
+}
+
+func (r *recordingObserver) OnDelete(k int, v string) {
+	r.events = append(r.events, fmt.Sprintf("delete %d=%s", k, v))
+}
+
+func (r *recordingObserver) OnKeyChange(oldKey, newKey int, v string) {
+	r.events = append(r.events, fmt.Sprintf("move %d->%d=%s", oldKey, newKey, v))
+}
+
+func TestTreeObserver(t *testing.T) {
+	a := assert.New(t)
+	obs := &recordingObserver{}
+	tree := NewComparable[int, string](WithCountChildren(true), WithObserver[int, string](obs))
+	tree.Insert(1, "a")
+	tree.Insert(2, "b")
+	tree.Insert(3, "c")
+	tree.Insert(4, "d")
+	tree.Insert(1, "A")
+	tree.Delete(2)
+	tree.Delete(100)
+	tree.DeleteAt(0)
+	tree.DeleteIterator(tree.IteratorAtLast())
+	tree.UpdateKey(3, 10)
+	tree.Insert(20, "x")
+	tree.UpdateKey(10, 20)
+	tree.UpdateKey(100, 200)
+	a.Equal([]string{
+		"insert 1=a",
+		"insert 2=b",
+		"insert 3=c",
+		"insert 4=d",
+		"update 1=a->A",
+		"delete 2=b",
+		"delete 1=A",
+		"delete 4=d",
+		"move 3->10=c",
+		"insert 20=x",
+		"delete 20=x",
+		"move 10->20=c",
+	}, obs.events)
+}
+
+func TestTreeObserverReplace(t *testing.T) {
+	a := assert.New(t)
+	src := NewComparable[int, string]()
+	src.Insert(1, "x")
+	src.Insert(2, "y")
+	data, err := src.GobEncode()
+	a.NoError(err)
+
+	obs := &recordingObserver{}
+	var journal bytes.Buffer
+	tree := NewComparable[int, string](WithObserver[int, string](obs),
+		WithJournal[int, string](&journal, JSONCodec[int]{}, JSONCodec[string]{}))
+	tree.Insert(3, "c")
+	tree.Insert(2, "b")
+	a.Error(tree.GobDecode(data[:len(data)-2]))
+	reversed := New[int, string](func(a, b int) int { return intCmp(b, a) }, WithObserver[int, string](obs))
+	a.True(errors.Is(reversed.GobDecode(data), ErrNotSorted))
+	journaled := journal.Len()
+	a.Equal(2, tree.Len())
+	a.Equal([]string{"insert 3=c", "insert 2=b"}, obs.events)
+
+	obs.events = nil
+	a.NoError(tree.GobDecode(data))
+	a.Equal([]string{"delete 2=b", "delete 3=c", "insert 1=x", "insert 2=y"}, obs.events)
+	a.Greater(journal.Len(), journaled)
+
+	obs.events = nil
+	tree.Clear()
+	a.Equal([]string{"delete 1=x", "delete 2=y"}, obs.events)
+}
+
+func TestTreeObserverSeveral(t *testing.T) {
+	a := assert.New(t)
+	var order []int
+	tree := NewComparable[int, int](
+		WithObserver[int, int](ObserverFuncs[int, int]{Insert: func(k, v int) { order = append(order, 1) }}),
+		WithObserver[int, int](ObserverFuncs[int, int]{Insert: func(k, v int) { order = append(order, 2) }}),
+	)
+	tree.Insert(1, 1)
+	tree.Delete(1)
+	a.Equal([]int{1, 2}, order)
+	a.Panics(func() {
+		NewComparable[int, int](WithObserver[string, int](ObserverFuncs[string, int]{}))
+	})
+}
+
+func TestTreeWatch(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, string]()
+	events, stop := tree.Watch(10, 20, 16)
+	tree.Insert(5, "out")
+	tree.Insert(10, "a")
+	tree.Insert(20, "b")
+	tree.Insert(21, "out")
+	tree.Insert(10, "c")
+	tree.Delete(20)
+	tree.UpdateKey(5, 15)
+	tree.UpdateKey(15, 30)
+	tree.Delete(30)
+	stop()
+	stop()
+	tree.Insert(11, "after stop")
+
+	var got []Event[int, string]
+	for e := range events {
+		got = append(got, e)
+	}
+	a.Equal([]Event[int, string]{
+		{Kind: EventInsert, Key: 10, Value: "a"},
+		{Kind: EventInsert, Key: 20, Value: "b"},
+		{Kind: EventUpdate, Key: 10, Value: "c", OldValue: "a"},
+		{Kind: EventDelete, Key: 20, Value: "b"},
+		{Kind: EventKeyChange, Key: 15, OldKey: 5, Value: "out"},
+		{Kind: EventKeyChange, Key: 30, OldKey: 15, Value: "out"},
+	}, got)
+	a.Empty(tree.observers)
+}
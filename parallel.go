@@ -0,0 +1,340 @@
+package goavl
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// parallelMinLen is the minimal number of elements worth building in a separate goroutine.
+	parallelMinLen = 1 << 12
+	// parallelMinHeight is the minimal height of a subtree worth processing in a separate goroutine.
+	parallelMinHeight = 12
+	// workerCacheSize is the number of nodes a parallel worker takes from a sync.Pool at once.
+	workerCacheSize = 64
+)
+
+var _ locationCache[int, int] = (*workerLocationCache[int, int])(nil)
+
+// workerLocationCache is the allocator of a goroutine taking part in a parallel operation on a tree
+// with WithSyncPool. It takes the nodes from the pool in batches and keeps the released ones,
+// so that the goroutines don't contend for the pool. The remaining nodes are put back by flush.
+type workerLocationCache[K, V any] struct {
+	p    *sync.Pool
+	free []*ptrNode[K, V]
+}
+
+func (lc *workerLocationCache[K, V]) new(k K, v V) location[K, V] { //nolint:unused // used in locationCache iface
+	if len(lc.free) == 0 {
+		lc.refill()
+	}
+	pn := &ptrNode[K, V]{}
+	if n := len(lc.free); n > 0 {
+		pn = lc.free[n-1]
+		lc.free = lc.free[:n-1]
+	}
+	pn.init(k, v)
+	return location[K, V]{
+		ptrNode: pn,
+	}
+}
+
+// refill takes up to workerCacheSize nodes from the pool, until it's empty.
+func (lc *workerLocationCache[K, V]) refill() {
+	for len(lc.free) < workerCacheSize {
+		pn, _ := lc.p.Get().(*ptrNode[K, V])
+		if pn == nil {
+			return
+		}
+		lc.free = append(lc.free, pn)
+	}
+}
+
+func (lc *workerLocationCache[K, V]) release(loc location[K, V]) { //nolint:unused // used in locationCache iface
+	pn := loc.ptrNode
+	*pn = ptrNode[K, V]{}
+	if len(lc.free) >= 2*workerCacheSize {
+		lc.p.Put(pn)
+		return
+	}
+	lc.free = append(lc.free, pn)
+}
+
+// flush puts the cached nodes back to the pool.
+func (lc *workerLocationCache[K, V]) flush() {
+	for i, pn := range lc.free {
+		lc.p.Put(pn)
+		lc.free[i] = nil
+	}
+	lc.free = lc.free[:0]
+}
+
+// parallelism returns the number of goroutines to use for an operation on t.
+// Arenas are not safe for concurrent use, so trees allocated in them are processed by a single goroutine.
+func (t *Tree[K, V, Cmp]) parallelism(n int) int {
+	switch {
+	case t.options.at == allocArenas:
+		return 1
+	case n <= 0:
+		return runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
+// newWorker returns a scratch tree for a goroutine taking part in a parallel operation on t.
+// It has the comparator and the options of t, but its own root, which is used by joins.
+// With WithSyncPool it also has its own allocator cache, call finish, when the goroutine is done.
+// The default allocator is safe for concurrent use, so it's shared.
+func (t *Tree[K, V, Cmp]) newWorker() *Tree[K, V, Cmp] {
+	w := &Tree[K, V, Cmp]{
+		options: t.options,
+		cmp:     t.cmp,
+		lc:      t.lc,
+		weight:  t.weight,
+	}
+	if plc, ok := t.lc.(*pooledLocationCache[K, V]); ok {
+		w.lc = &workerLocationCache[K, V]{p: plc.p}
+	}
+	return w
+}
+
+// finish returns the nodes cached by the worker w to the pool.
+func (t *Tree[K, V, Cmp]) finish() {
+	if wlc, ok := t.lc.(*workerLocationCache[K, V]); ok {
+		wlc.flush()
+	}
+}
+
+// derivedOptions returns the options of a tree built from t's nodes by a parallel operation.
+// Journals, observers, eviction and comparator checks are not inherited.
+func derivedOptions(o Options) Options {
+	result := allocatorOptions(o)
+	result.countChildren = o.countChildren
+	result.paranoid = o.paranoid
+	return result
+}
+
+// fork runs f and g concurrently with par goroutines in total, if par > 1, or sequentially otherwise.
+// separate tells g, whether it runs in a separate goroutine. A panic in g is propagated to the caller.
+func fork(par int, f func(par int), g func(par int, separate bool)) {
+	if par < 2 {
+		f(1)
+		g(1, false)
+		return
+	}
+	done := make(chan any, 1)
+	go func() {
+		defer func() {
+			done <- recover()
+		}()
+		g(par/2, true)
+	}()
+	f(par - par/2)
+	if p := <-done; p != nil {
+		panic(p)
+	}
+}
+
+// setBuiltRoot makes root the root of the empty tree t with n elements.
+// Journal and observers are notified about the elements in ascending order.
+func (t *Tree[K, V, Cmp]) setBuiltRoot(root location[K, V], n int) {
+	t.setRoot(root)
+	t.min, t.max = goLeft(root), goRight(root)
+	t.length = n
+	if t.journal.active() || len(t.observers) > 0 {
+		for loc := t.min; !loc.isNil(); loc = nextLocation(loc) {
+			t.journal.insert(loc.key(), *loc.valuePtr())
+			t.notifyInsert(loc.key(), *loc.valuePtr())
+		}
+	}
+	t.checkInvariants()
+}
+
+// ParallelBuild returns a new tree with the given keys and values, built by up to parallelism goroutines.
+// Use parallelism <= 0 for runtime.GOMAXPROCS(0).
+// The keys must be in strictly ascending order, otherwise ErrNotSorted is returned.
+// cmp may be called concurrently.
+// Returns ErrCapacityExceeded or ErrCountOverflow, if the tree can't hold all the elements.
+// The resulting tree is perfectly balanced.
+// With WithSyncPool every goroutine takes nodes from the pool in batches and puts the unused ones back.
+// Panics if keys and values have different lengths.
+// Time complexity: O(n/p + logn), where p is the parallelism.
+func ParallelBuild[K, V any, Cmp func(a, b K) int](cmp Cmp, keys []K, values []V, parallelism int, opts ...Option) (*Tree[K, V, Cmp], error) {
+	if len(keys) != len(values) {
+		panic("goavl: keys and values have different lengths")
+	}
+	t := New[K, V](cmp, opts...)
+	n := len(keys)
+	if err := t.checkBulkLen(n); err != nil {
+		return nil, err
+	}
+	b := parallelBuilder[K, V, Cmp]{t: t, keys: keys, values: values}
+	w := t.newWorker()
+	root := b.build(w, 0, n, t.parallelism(parallelism))
+	w.finish()
+	if b.notSorted.Load() {
+		return nil, ErrNotSorted
+	}
+	t.nextID = uint64(n)
+	t.setBuiltRoot(root, n)
+	return t, nil
+}
+
+type parallelBuilder[K, V any, Cmp func(a, b K) int] struct {
+	t         *Tree[K, V, Cmp]
+	keys      []K
+	values    []V
+	notSorted atomic.Bool
+}
+
+// build returns the root of a balanced subtree built from the elements on [lo, hi).
+// The element at i has ID i+1.
+func (b *parallelBuilder[K, V, Cmp]) build(w *Tree[K, V, Cmp], lo, hi, par int) location[K, V] {
+	if lo >= hi || b.notSorted.Load() {
+		return location[K, V]{}
+	}
+	mid := lo + (hi-lo-1)/2
+	if mid > 0 && b.t.cmp(b.keys[mid-1], b.keys[mid]) >= 0 {
+		b.notSorted.Store(true)
+		return location[K, V]{}
+	}
+	var left, right location[K, V]
+	if hi-lo < parallelMinLen {
+		par = 1
+	}
+	fork(par, func(par int) {
+		left = b.build(w, lo, mid, par)
+	}, func(par int, separate bool) {
+		rw := w
+		if separate {
+			rw = b.t.newWorker()
+			defer rw.finish()
+		}
+		right = b.build(rw, mid+1, hi, par)
+	})
+	loc := w.lc.new(b.keys[mid], b.values[mid])
+	loc.setID(uint64(mid) + 1)
+	loc.setLeft(left)
+	loc.setRight(right)
+	loc.recalcHeight()
+	w.recalcAugments(loc)
+	return loc
+}
+
+// ParallelFilter returns a new tree with the elements of t, for which f returns true.
+// The subtrees are filtered by up to parallelism goroutines and the results are joined.
+// Use parallelism <= 0 for runtime.GOMAXPROCS(0).
+// f may be called concurrently and in any order, and must not modify t.
+// The new tree has the comparator, children counts, weights and the allocator of t,
+// but not its journal, observers or capacity.
+// Time complexity: O((n + m*logn)/p), where m is the number of dropped elements and p is the parallelism.
+func ParallelFilter[K, V any, Cmp func(a, b K) int](t *Tree[K, V, Cmp], f func(k K, v *V) bool, parallelism int) *Tree[K, V, Cmp] {
+	options := derivedOptions(t.options)
+	options.weight = t.options.weight
+	result := newWithOptions[K, V](t.cmp, options)
+	var n atomic.Int64
+	w := result.newWorker()
+	root := filterSubtree(result, w, t.root, f, &n, t.parallelism(parallelism))
+	w.finish()
+	result.nextID = t.nextID
+	result.setBuiltRoot(root, int(n.Load()))
+	return result
+}
+
+// filterSubtree returns the root of a new subtree with the elements of src, for which f returns true.
+// The nodes keep the IDs of the source ones.
+func filterSubtree[K, V any, Cmp func(a, b K) int](result, w *Tree[K, V, Cmp], src location[K, V], f func(k K, v *V) bool, n *atomic.Int64, par int) location[K, V] {
+	if src.isNil() {
+		return src
+	}
+	var left, right location[K, V]
+	if src.height() < parallelMinHeight {
+		par = 1
+	}
+	fork(par, func(par int) {
+		left = filterSubtree(result, w, src.left(), f, n, par)
+	}, func(par int, separate bool) {
+		rw := w
+		if separate {
+			rw = result.newWorker()
+			defer rw.finish()
+		}
+		right = filterSubtree(result, rw, src.right(), f, n, par)
+	})
+	if !f(src.key(), src.valuePtr()) {
+		return w.join2(left, right)
+	}
+	n.Add(1)
+	mid := w.lc.new(src.key(), *src.valuePtr())
+	mid.setID(src.id())
+	return detachRoot(w.joinRoots(left, mid, right))
+}
+
+// join2 joins the subtrees l and r, where all the keys of l are less than the keys of r.
+// Time complexity: O(logn).
+func (t *Tree[K, V, Cmp]) join2(l, r location[K, V]) location[K, V] {
+	if r.isNil() {
+		return l
+	}
+	first, rest := t.splitFirst(r)
+	return detachRoot(t.joinRoots(l, first, rest))
+}
+
+// splitFirst detaches the first node of the subtree rooted at loc.
+// Returns the node and the root of a balanced subtree with the rest of the nodes.
+// Time complexity: O(logn).
+func (t *Tree[K, V, Cmp]) splitFirst(loc location[K, V]) (first, rest location[K, V]) {
+	left := loc.left()
+	if left.isNil() {
+		return loc, detachRoot(loc.right())
+	}
+	first, rest = t.splitFirst(left)
+	return first, detachRoot(t.joinRoots(rest, loc, loc.right()))
+}
+
+// ParallelMapValues returns a new tree with the keys of t and the values returned by f.
+// The new tree has the same shape as t and is built by up to parallelism goroutines.
+// Use parallelism <= 0 for runtime.GOMAXPROCS(0).
+// f may be called concurrently and in any order, and must not modify t.
+// The new tree has the comparator, children counts and the allocator of t,
+// but not its weights, journal, observers or capacity.
+// Time complexity: O(n/p + logn), where p is the parallelism.
+func ParallelMapValues[K, V, W any, Cmp func(a, b K) int](t *Tree[K, V, Cmp], f func(k K, v *V) W, parallelism int) *Tree[K, W, Cmp] {
+	result := newWithOptions[K, W](t.cmp, derivedOptions(t.options))
+	w := result.newWorker()
+	root := mapSubtree(result, w, t.root, f, t.parallelism(parallelism))
+	w.finish()
+	result.nextID = t.nextID
+	result.setBuiltRoot(root, t.length)
+	return result
+}
+
+// mapSubtree returns the root of a copy of the subtree src with the values returned by f.
+// The nodes keep the IDs of the source ones.
+func mapSubtree[K, V, W any, Cmp func(a, b K) int](result, w *Tree[K, W, Cmp], src location[K, V], f func(k K, v *V) W, par int) location[K, W] {
+	if src.isNil() {
+		return location[K, W]{}
+	}
+	var left, right location[K, W]
+	if src.height() < parallelMinHeight {
+		par = 1
+	}
+	fork(par, func(par int) {
+		left = mapSubtree(result, w, src.left(), f, par)
+	}, func(par int, separate bool) {
+		rw := w
+		if separate {
+			rw = result.newWorker()
+			defer rw.finish()
+		}
+		right = mapSubtree(result, rw, src.right(), f, par)
+	})
+	loc := w.lc.new(src.key(), f(src.key(), src.valuePtr()))
+	loc.setID(src.id())
+	loc.setLeft(left)
+	loc.setRight(right)
+	loc.recalcHeight()
+	w.recalcAugments(loc)
+	return loc
+}
@@ -0,0 +1,178 @@
+package goavl
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sortedInts(n int) (keys, values []int) {
+	for i := 0; i < n; i++ {
+		keys = append(keys, 2*i)
+		values = append(values, i)
+	}
+	return keys, values
+}
+
+func treeEntries[K, V any, Cmp func(a, b K) int](t *Tree[K, V, Cmp]) (keys []K, values []V) {
+	for loc := t.min; !loc.isNil(); loc = nextLocation(loc) {
+		keys = append(keys, loc.key())
+		values = append(values, *loc.valuePtr())
+	}
+	return keys, values
+}
+
+func TestParallelBuild(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 100, parallelMinLen*5 + 3} {
+		for _, parallelism := range []int{0, 1, 3, 8} {
+			a := assert.New(t)
+			keys, values := sortedInts(n)
+			var inserted atomic.Int64
+			tree, err := ParallelBuild(intCmp, keys, values, parallelism,
+				WithCountChildren(true), withTestWeights(), WithSyncPool(nil),
+				WithObserver[int, int](ObserverFuncs[int, int]{
+					Insert: func(int, int) { inserted.Add(1) },
+				}))
+			a.NoError(err)
+			a.NoError(tree.Validate())
+			a.Equal(n, tree.Len())
+			a.Equal(int64(n), inserted.Load())
+			gotKeys, gotValues := treeEntries(tree)
+			a.Equal(keys, gotKeys)
+			a.Equal(values, gotValues)
+			if n == 0 {
+				continue
+			}
+			a.Equal(keys[n/2], tree.At(n/2).Key)
+			if weightsEnabled {
+				a.Equal(uint64(n*(n-1)/2), tree.TotalWeight())
+			}
+
+			tree.Insert(-1, 0)
+			tree.Delete(keys[n-1])
+			a.NoError(tree.Validate())
+		}
+	}
+}
+
+func TestParallelBuildSyncPool(t *testing.T) {
+	a := assert.New(t)
+	pool := &sync.Pool{}
+	keys, values := sortedInts(parallelMinLen * 3)
+	tree, err := ParallelBuild(intCmp, keys, values, 4, WithSyncPool(pool))
+	a.NoError(err)
+	w := tree.newWorker()
+	wlc, ok := w.lc.(*workerLocationCache[int, int])
+	a.True(ok)
+	a.Same(pool, wlc.p)
+	w.Insert(1, 1)
+	w.finish()
+	a.Empty(wlc.free)
+	_, ok = New[int, int](intCmp).newWorker().lc.(*workerLocationCache[int, int])
+	a.False(ok)
+	for i, k := range keys {
+		if i%3 != 0 {
+			tree.Delete(k)
+		}
+	}
+	a.NoError(tree.Validate())
+	a.Equal((len(keys)+2)/3, tree.Len())
+	pn, _ := pool.Get().(*ptrNode[int, int])
+	a.NotNil(pn)
+	a.Equal(ptrNode[int, int]{}, *pn)
+
+	// ParallelFilter takes the nodes from the pool too.
+	filtered := ParallelFilter(tree, func(k int, _ *int) bool { return k%2 == 0 }, 4)
+	a.NoError(filtered.Validate())
+	a.Equal(tree.Len(), filtered.Len())
+	for _, k := range keys {
+		filtered.Delete(k)
+	}
+	a.Zero(filtered.Len())
+}
+
+func TestParallelBuildErrors(t *testing.T) {
+	a := assert.New(t)
+	keys, values := sortedInts(parallelMinLen * 3)
+	for _, i := range []int{1, len(keys) / 2, len(keys) - 1} {
+		broken := append([]int(nil), keys...)
+		broken[i] = broken[i-1]
+		_, err := ParallelBuild(intCmp, broken, values, 4)
+		a.ErrorIs(err, ErrNotSorted)
+	}
+	_, err := ParallelBuild(intCmp, keys, values, 4, WithCapacity(10, EvictMin))
+	a.ErrorIs(err, ErrCapacityExceeded)
+	a.Panics(func() {
+		ParallelBuild(intCmp, keys, values[1:], 4)
+	})
+}
+
+func TestParallelFilterAndMap(t *testing.T) {
+	a := assert.New(t)
+	r := rand.New(rand.NewSource(19))
+	tree := New[int, int](intCmp, WithCountChildren(true), withTestWeights())
+	for i := 0; i < 30000; i++ {
+		tree.Insert(r.Intn(100000), r.Intn(100))
+	}
+	srcKeys, srcValues := treeEntries(tree)
+	for _, parallelism := range []int{0, 1, 2, 5} {
+		for name, keep := range map[string]func(k int, v *int) bool{
+			"none": func(int, *int) bool { return false },
+			"all":  func(int, *int) bool { return true },
+			"even": func(k int, _ *int) bool { return k%2 == 0 },
+			"rare": func(_ int, v *int) bool { return *v == 7 },
+			"half": func(k int, _ *int) bool { return k < 50000 },
+		} {
+			filtered := ParallelFilter(tree, keep, parallelism)
+			a.NoErrorf(filtered.Validate(), "%s/%d", name, parallelism)
+			var wantKeys []int
+			var wantWeight uint64
+			for i, k := range srcKeys {
+				v := srcValues[i]
+				if keep(k, &v) {
+					wantKeys = append(wantKeys, k)
+					wantWeight += uint64(v)
+				}
+			}
+			gotKeys, _ := treeEntries(filtered)
+			a.Equalf(wantKeys, gotKeys, "%s/%d", name, parallelism)
+			a.Equal(len(wantKeys), filtered.Len())
+			if weightsEnabled {
+				a.Equal(wantWeight, filtered.TotalWeight())
+			}
+		}
+
+		mapped := ParallelMapValues(tree, func(k int, v *int) string {
+			return fmt.Sprint(k, ":", *v)
+		}, parallelism)
+		a.NoError(mapped.Validate())
+		a.Equal(tree.Len(), mapped.Len())
+		a.Equal(tree.root.height(), mapped.root.height())
+		gotKeys, gotValues := treeEntries(mapped)
+		a.Equal(srcKeys, gotKeys)
+		for i, k := range srcKeys {
+			a.Equal(fmt.Sprint(k, ":", srcValues[i]), gotValues[i])
+		}
+		mapped.Insert(-1, "x")
+		a.NoError(mapped.Validate())
+	}
+	a.NoError(tree.Validate())
+	gotKeys, gotValues := treeEntries(tree)
+	a.Equal(srcKeys, gotKeys)
+	a.Equal(srcValues, gotValues)
+	a.True(sort.IntsAreSorted(gotKeys))
+
+	a.PanicsWithValue("boom", func() {
+		ParallelFilter(tree, func(k int, _ *int) bool {
+			if k == srcKeys[len(srcKeys)-1] {
+				panic("boom")
+			}
+			return true
+		}, 4)
+	})
+}
@@ -0,0 +1,68 @@
+package goavl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrOutOfRange is matched by OutOfRangeError with errors.Is.
+var ErrOutOfRange = errors.New("goavl: index out of range")
+
+// OutOfRangeError is the panic value of the positional functions, like At, IteratorAt and DeleteAt,
+// called with a position out of [0, Len).
+type OutOfRangeError struct {
+	// Index is the requested position.
+	Index int
+	// Len is the length of the tree.
+	Len int
+}
+
+func (e *OutOfRangeError) Error() string {
+	return fmt.Sprintf("goavl: index %d out of range [0, %d)", e.Index, e.Len)
+}
+
+// Is returns true for ErrOutOfRange.
+func (e *OutOfRangeError) Is(target error) bool {
+	return target == ErrOutOfRange
+}
+
+func (t *Tree[K, V, Cmp]) inRange(position int) bool {
+	return position >= 0 && position < t.length
+}
+
+// TryAt is like At, but returns false instead of panicking, if the position is out of range.
+// Time complexity:
+//
+//	O(logn) - if children node counts are enabled.
+//	O(n) - otherwise.
+func (t *Tree[K, V, Cmp]) TryAt(position int) (entry Entry[K, V], ok bool) {
+	if !t.inRange(position) {
+		return entry, false
+	}
+	return t.At(position), true
+}
+
+// TryIteratorAt is like IteratorAt, but returns false instead of panicking, if the position is out of range.
+// Time complexity:
+//
+//	O(logn) - if children node counts are enabled.
+//	O(n) - otherwise.
+func (t *Tree[K, V, Cmp]) TryIteratorAt(position int) (it Iterator[K, V, Cmp], ok bool) {
+	if !t.inRange(position) {
+		return it, false
+	}
+	return t.IteratorAt(position), true
+}
+
+// TryDeleteAt is like DeleteAt, but returns false instead of panicking, if the position is out of range.
+// Time complexity:
+//
+//	O(logn) - if children node counts are enabled.
+//	O(n) - otherwise.
+func (t *Tree[K, V, Cmp]) TryDeleteAt(position int) (k K, v V, ok bool) {
+	if !t.inRange(position) {
+		return k, v, false
+	}
+	k, v = t.DeleteAt(position)
+	return k, v, true
+}
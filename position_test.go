@@ -0,0 +1,69 @@
+package goavl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeTryPositional(t *testing.T) {
+	for _, countChildren := range []bool{false, true} {
+		a := assert.New(t)
+		tree := NewComparable[int, int](WithCountChildren(countChildren))
+		_, ok := tree.TryAt(0)
+		a.False(ok)
+		for i := 0; i < 32; i++ {
+			tree.Insert(i, i*10)
+		}
+		for _, pos := range []int{-1, 32, 100} {
+			_, ok = tree.TryAt(pos)
+			a.False(ok)
+			_, ok = tree.TryIteratorAt(pos)
+			a.False(ok)
+			_, _, ok = tree.TryDeleteAt(pos)
+			a.False(ok)
+		}
+		a.Equal(32, tree.Len())
+
+		e, ok := tree.TryAt(5)
+		a.True(ok)
+		a.Equal(5, e.Key)
+		a.Equal(50, *e.Value)
+		it, ok := tree.TryIteratorAt(31)
+		a.True(ok)
+		e, _ = it.Value()
+		a.Equal(31, e.Key)
+		k, v, ok := tree.TryDeleteAt(0)
+		a.True(ok)
+		a.Equal(0, k)
+		a.Equal(0, v)
+		a.Equal(31, tree.Len())
+	}
+}
+
+func TestTreeOutOfRangePanic(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int]()
+	tree.Insert(1, 1)
+	for _, f := range []func(){
+		func() { tree.At(1) },
+		func() { tree.IteratorAt(-1) },
+		func() { tree.DeleteAt(5) },
+	} {
+		func() {
+			defer func() {
+				err, ok := recover().(error)
+				a.True(ok)
+				a.True(errors.Is(err, ErrOutOfRange))
+				var oor *OutOfRangeError
+				a.True(errors.As(err, &oor))
+				a.Equal(1, oor.Len)
+			}()
+			f()
+		}()
+	}
+	a.PanicsWithError("goavl: index 5 out of range [0, 1)", func() {
+		tree.DeleteAt(5)
+	})
+}
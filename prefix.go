@@ -0,0 +1,68 @@
+package goavl
+
+// bytesKey is a string or a byte slice key.
+type bytesKey interface {
+	~string | ~[]byte
+}
+
+// PrefixEach calls f for every element whose key starts with p in ascending key order, until f returns false.
+// The comparator must be byte-lexicographic, like strings.Compare or bytes.Compare.
+// The scan starts at LowerBound(p) and stops at the smallest key greater than all the keys with prefix p.
+// The tree must not be modified during the iteration.
+// Time complexity: O(logn + m), where m is the number of matching elements.
+func PrefixEach[K bytesKey, V any, Cmp func(a, b K) int](t *Tree[K, V, Cmp], p K, f func(k K, v *V) bool) {
+	end, bounded := prefixSuccessor(p)
+	for loc := t.LowerBound(p).loc; !loc.isNil(); loc = nextLocation(loc) {
+		if bounded && t.cmp(loc.key(), end) >= 0 {
+			return
+		}
+		if !f(loc.key(), loc.valuePtr()) {
+			return
+		}
+	}
+}
+
+// LongestPrefixOf returns the element with the longest key that is a prefix of q.
+// The comparator must be byte-lexicographic, like strings.Compare or bytes.Compare.
+// Returns false if no key is a prefix of q.
+// Time complexity: O(logn) per byte of q in the worst case, O(logn) if Floor(q) is a prefix of q.
+func LongestPrefixOf[K bytesKey, V any, Cmp func(a, b K) int](t *Tree[K, V, Cmp], q K) (entry Entry[K, V], found bool) {
+	qb := []byte(q)
+	for {
+		loc := t.Floor(K(qb)).loc
+		if loc.isNil() {
+			return entry, false
+		}
+		k := []byte(loc.key())
+		n := commonPrefixLen(k, qb)
+		if n == len(k) {
+			return Entry[K, V]{Key: loc.key(), Value: loc.valuePtr()}, true
+		}
+		// k < qb and differs from it at position n, so any key that is a prefix of qb,
+		// but is longer than n, would be between k and qb.
+		qb = qb[:n]
+	}
+}
+
+// prefixSuccessor returns the smallest key greater than all the keys starting with p.
+// Returns false if there is no such key, i.e. p is empty or consists of 0xff bytes only.
+func prefixSuccessor[K bytesKey](p K) (K, bool) {
+	b := []byte(string(p))
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] != 0xff {
+			b = b[:i+1]
+			b[i]++
+			return K(b), true
+		}
+	}
+	var zero K
+	return zero, false
+}
+
+func commonPrefixLen(a, b []byte) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n] == b[n] {
+		n++
+	}
+	return n
+}
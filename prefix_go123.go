@@ -0,0 +1,15 @@
+//go:build go1.23
+
+package goavl
+
+import "iter"
+
+// PrefixScan returns an iterator over the elements whose keys start with p in ascending key order.
+// It can be used in a for-range loop (Go 1.23+). See PrefixEach for the details.
+func PrefixScan[K bytesKey, V any, Cmp func(a, b K) int](t *Tree[K, V, Cmp], p K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		PrefixEach(t, p, func(k K, v *V) bool {
+			return yield(k, *v)
+		})
+	}
+}
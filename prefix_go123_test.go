@@ -0,0 +1,28 @@
+//go:build go1.23
+
+package goavl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixScan(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[string, int]()
+	for i, k := range []string{"usr", "usr/bin", "usr/lib", "var"} {
+		tree.Insert(k, i)
+	}
+	var keys []string
+	var values []int
+	for k, v := range PrefixScan(tree, "usr/") {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+	a.Equal([]string{"usr/bin", "usr/lib"}, keys)
+	a.Equal([]int{1, 2}, values)
+	for range PrefixScan(tree, "usr") {
+		break
+	}
+}
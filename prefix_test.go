@@ -0,0 +1,130 @@
+package goavl
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func prefixKeys[K bytesKey, V any, Cmp func(a, b K) int](t *Tree[K, V, Cmp], p K) []string {
+	var result []string
+	PrefixEach(t, p, func(k K, _ *V) bool {
+		result = append(result, string(k))
+		return true
+	})
+	return result
+}
+
+func TestPrefixEach(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[string, int]()
+	a.Nil(prefixKeys(tree, "a"))
+	for i, k := range []string{"", "a", "ab", "abc", "abd", "ac", "b", "a\xff", "a\xff\xff", "a\xff\xffz", "b\x00", "\xff", "\xff\xff"} {
+		tree.Insert(k, i)
+	}
+	a.Equal([]string{"ab", "abc", "abd"}, prefixKeys(tree, "ab"))
+	a.Equal([]string{"abc"}, prefixKeys(tree, "abc"))
+	a.Nil(prefixKeys(tree, "abe"))
+	a.Equal([]string{"a\xff", "a\xff\xff", "a\xff\xffz"}, prefixKeys(tree, "a\xff"))
+	a.Equal([]string{"a\xff\xff", "a\xff\xffz"}, prefixKeys(tree, "a\xff\xff"))
+	a.Equal([]string{"b", "b\x00"}, prefixKeys(tree, "b"))
+	a.Equal([]string{"\xff", "\xff\xff"}, prefixKeys(tree, "\xff"))
+	a.Len(prefixKeys(tree, ""), tree.Len())
+
+	var visited []string
+	PrefixEach(tree, "a", func(k string, _ *int) bool {
+		visited = append(visited, k)
+		return len(visited) < 2
+	})
+	a.Equal([]string{"a", "ab"}, visited)
+
+	bt := New[[]byte, int](bytes.Compare)
+	for _, k := range []string{"x", "xy", "xyz", "y"} {
+		bt.Insert([]byte(k), 0)
+	}
+	p := []byte("xy\xffq")[:2]
+	a.Equal([]string{"xy", "xyz"}, prefixKeys(bt, p))
+	a.Equal("xy\xffq", string(p[:4]), "the prefix must not be modified")
+}
+
+func TestLongestPrefixOf(t *testing.T) {
+	a := assert.New(t)
+	tree := New[string, int](strings.Compare)
+	_, found := LongestPrefixOf(tree, "abc")
+	a.False(found)
+	for i, k := range []string{"/a", "/a/b", "/a/b/c/d", "/a/bb", "/a/c", "/z"} {
+		tree.Insert(k, i)
+	}
+	for q, want := range map[string]string{
+		"/a":       "/a",
+		"/a/b/c":   "/a/b",
+		"/a/b/c/d": "/a/b/c/d",
+		"/a/bz":    "/a/b",
+		"/a/ba":    "/a/b",
+		"/a/c/x":   "/a/c",
+		"/aa":      "/a",
+		"/y":       "",
+		"/":        "",
+		"":         "",
+	} {
+		e, found := LongestPrefixOf(tree, q)
+		a.Equalf(want != "", found, "q = %q", q)
+		if found {
+			a.Equalf(want, e.Key, "q = %q", q)
+		}
+	}
+	tree.Insert("", -1)
+	e, found := LongestPrefixOf(tree, "/y")
+	a.True(found)
+	a.Equal("", e.Key)
+	a.Equal(-1, *e.Value)
+
+	bt := New[[]byte, int](bytes.Compare)
+	bt.Insert([]byte("ab"), 1)
+	e2, found := LongestPrefixOf(bt, []byte("abc"))
+	a.True(found)
+	a.Equal([]byte("ab"), e2.Key)
+}
+
+func TestPrefixRandom(t *testing.T) {
+	a := assert.New(t)
+	r := rand.New(rand.NewSource(11))
+	randKey := func() string {
+		b := make([]byte, r.Intn(5))
+		for i := range b {
+			b[i] = "ab\x00\xff"[r.Intn(4)]
+		}
+		return string(b)
+	}
+	tree := NewComparable[string, struct{}]()
+	keys := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		k := randKey()
+		tree.Insert(k, struct{}{})
+		keys[k] = true
+	}
+	for i := 0; i < 200; i++ {
+		q := randKey()
+		var want []string
+		longest, found := "", false
+		for k := range keys {
+			if strings.HasPrefix(k, q) {
+				want = append(want, k)
+			}
+			if strings.HasPrefix(q, k) && (!found || len(k) > len(longest)) {
+				longest, found = k, true
+			}
+		}
+		sort.Strings(want)
+		a.Equalf(want, prefixKeys(tree, q), "q = %q", q)
+		e, ok := LongestPrefixOf(tree, q)
+		a.Equal(found, ok)
+		if found {
+			a.Equalf(longest, e.Key, "q = %q", q)
+		}
+	}
+}
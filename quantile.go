@@ -0,0 +1,195 @@
+package goavl
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+// QuantileMethod defines how a quantile is computed, if it lies between two elements.
+// For a tree of n elements, the q-quantile is at position h = q*(n-1) of the sorted sequence.
+type QuantileMethod int8
+
+const (
+	// QuantileLinear interpolates linearly between the elements at floor(h) and ceil(h).
+	QuantileLinear QuantileMethod = iota
+	// QuantileLower returns the element at floor(h).
+	QuantileLower
+	// QuantileHigher returns the element at ceil(h).
+	QuantileHigher
+	// QuantileNearest returns the element at h rounded to the nearest integer, with halves rounded to even.
+	QuantileNearest
+	// QuantileMidpoint returns the mean of the elements at floor(h) and ceil(h).
+	QuantileMidpoint
+)
+
+type number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Median returns the median of the keys of the tree, interpolated linearly for even lengths.
+// Returns false if the tree is empty.
+// Time complexity:
+//
+//	O(logn) - if children node counts are enabled.
+//	O(n) - otherwise.
+func Median[K number, V any, Cmp func(a, b K) int](t *Tree[K, V, Cmp]) (median float64, found bool) {
+	return Quantile(t, 0.5, QuantileLinear)
+}
+
+// Quantile returns the q-quantile of the keys of the tree.
+// The result is a float64, which can't represent integers above 2^53 exactly.
+// To get the key itself, use Tree.QuantileEntry with QuantileLower, QuantileHigher or QuantileNearest.
+// Returns false if the tree is empty. Panics if q is out of [0, 1], or the method is unknown.
+// Time complexity:
+//
+//	O(logn) - if children node counts are enabled.
+//	O(n) - otherwise.
+func Quantile[K number, V any, Cmp func(a, b K) int](t *Tree[K, V, Cmp], q float64, method QuantileMethod) (quantile float64, found bool) {
+	result := Quantiles(t, []float64{q}, method)
+	if result == nil {
+		return 0, false
+	}
+	return result[0], true
+}
+
+// Quantiles returns the quantiles of the keys of the tree for every element of qs.
+// The elements are located together: the tree is descended once per distinct position, or,
+// if children counts are disabled, traversed once.
+// The results are float64s, see Quantile.
+// Returns nil if the tree is empty. Panics if any of qs is out of [0, 1], or the method is unknown.
+// Time complexity:
+//
+//	O(mlogn) - if children node counts are enabled.
+//	O(n + mlogm) - otherwise.
+func Quantiles[K number, V any, Cmp func(a, b K) int](t *Tree[K, V, Cmp], qs []float64, method QuantileMethod) []float64 {
+	checkQuantileMethod(method)
+	checkQuantiles(qs)
+	if t.length == 0 {
+		return nil
+	}
+	qb := make([]quantileBounds, len(qs))
+	positions := make([]int, 0, 2*len(qs))
+	for i, q := range qs {
+		qb[i] = newQuantileBounds(q, t.length, method)
+		positions = append(positions, qb[i].lo, qb[i].hi)
+	}
+	locs := t.locationsAt(positions)
+	result := make([]float64, len(qs))
+	for i, b := range qb {
+		lo, hi := float64(locs[b.lo].key()), float64(locs[b.hi].key())
+		switch method {
+		case QuantileLinear:
+			result[i] = lo + (b.h-float64(b.lo))*(hi-lo)
+		case QuantileMidpoint:
+			result[i] = (lo + hi) / 2
+		default:
+			result[i] = lo
+		}
+	}
+	return result
+}
+
+// QuantileEntry returns the element at the q-quantile position of the tree.
+// Unlike Quantile, it works for any keys, and the key is returned as is.
+// method must be QuantileLower, QuantileHigher or QuantileNearest.
+// Returns false if the tree is empty. Panics if q is out of [0, 1], or the method interpolates.
+// Time complexity:
+//
+//	O(logn) - if children node counts are enabled.
+//	O(n) - otherwise.
+func (t *Tree[K, V, Cmp]) QuantileEntry(q float64, method QuantileMethod) (entry Entry[K, V], found bool) {
+	result := t.QuantileEntries([]float64{q}, method)
+	if result == nil {
+		return entry, false
+	}
+	return result[0], true
+}
+
+// QuantileEntries returns the elements at the quantile positions of the tree for every element of qs,
+// see QuantileEntry. The elements are located together, like Quantiles does.
+// Returns nil if the tree is empty. Panics if any of qs is out of [0, 1], or the method interpolates.
+// Time complexity:
+//
+//	O(mlogn) - if children node counts are enabled.
+//	O(n + mlogm) - otherwise.
+func (t *Tree[K, V, Cmp]) QuantileEntries(qs []float64, method QuantileMethod) []Entry[K, V] {
+	checkQuantileMethod(method)
+	if method != QuantileLower && method != QuantileHigher && method != QuantileNearest {
+		panic(fmt.Sprintf("goavl: quantile method %d doesn't select an element", method))
+	}
+	checkQuantiles(qs)
+	if t.length == 0 {
+		return nil
+	}
+	positions := make([]int, len(qs))
+	for i, q := range qs {
+		positions[i] = newQuantileBounds(q, t.length, method).lo
+	}
+	locs := t.locationsAt(positions)
+	result := make([]Entry[K, V], len(qs))
+	for i, pos := range positions {
+		result[i] = Entry[K, V]{Key: locs[pos].key(), Value: locs[pos].valuePtr()}
+	}
+	return result
+}
+
+func checkQuantileMethod(method QuantileMethod) {
+	if method < QuantileLinear || method > QuantileMidpoint {
+		panic(fmt.Sprintf("goavl: unknown quantile method %d", method))
+	}
+}
+
+func checkQuantiles(qs []float64) {
+	for _, q := range qs {
+		if !(q >= 0 && q <= 1) {
+			panic(fmt.Sprintf("goavl: quantile %v is out of [0, 1]", q))
+		}
+	}
+}
+
+// quantileBounds are the positions of the elements a quantile is computed from.
+type quantileBounds struct {
+	h      float64
+	lo, hi int
+}
+
+func newQuantileBounds(q float64, n int, method QuantileMethod) quantileBounds {
+	h := q * float64(n-1)
+	lo, hi := int(math.Floor(h)), int(math.Ceil(h))
+	switch method {
+	case QuantileLower:
+		hi = lo
+	case QuantileHigher:
+		lo = hi
+	case QuantileNearest:
+		lo = int(math.RoundToEven(h))
+		hi = lo
+	}
+	return quantileBounds{h: h, lo: lo, hi: hi}
+}
+
+// locationsAt returns the locations at the given positions, which must be valid.
+func (t *Tree[K, V, Cmp]) locationsAt(positions []int) map[int]location[K, V] {
+	sorted := append([]int(nil), positions...)
+	sort.Ints(sorted)
+	result := make(map[int]location[K, V], len(sorted))
+	var loc location[K, V]
+	prev := -1
+	for _, pos := range sorted {
+		if pos == prev {
+			continue
+		}
+		// without children counts, or for close positions, it's cheaper to move from the previous one.
+		if !loc.isNil() && (!t.options.countChildren || pos-prev <= 8) {
+			loc = advance(loc, pos-prev)
+		} else {
+			loc = t.locateAt(pos)
+		}
+		result[pos] = loc
+		prev = pos
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package goavl
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantile(t *testing.T) {
+	for _, countChildren := range []bool{false, true} {
+		a := assert.New(t)
+		tree := NewComparable[int, struct{}](WithCountChildren(countChildren))
+		_, found := Median(tree)
+		a.False(found)
+		a.Nil(Quantiles(tree, []float64{0.5}, QuantileLinear))
+
+		for _, k := range []int{40, 10, 30, 20} {
+			tree.Insert(k, struct{}{})
+		}
+		median, found := Median(tree)
+		a.True(found)
+		a.Equal(25.0, median)
+		for method, want := range map[QuantileMethod]float64{
+			QuantileLinear:   25,
+			QuantileLower:    20,
+			QuantileHigher:   30,
+			QuantileNearest:  30,
+			QuantileMidpoint: 25,
+		} {
+			q, _ := Quantile(tree, 0.5, method)
+			a.Equalf(want, q, "method %d", method)
+		}
+		a.Equal([]float64{10, 17.5, 40, 10}, Quantiles(tree, []float64{0, 0.25, 1, 0}, QuantileLinear))
+		a.Equal([]float64{10, 20, 40}, Quantiles(tree, []float64{0, 0.25, 1}, QuantileHigher))
+		for _, q := range []float64{-0.1, 1.5, math.NaN()} {
+			a.Panics(func() {
+				Quantile(tree, q, QuantileLinear)
+			})
+		}
+		for _, method := range []QuantileMethod{-1, QuantileMidpoint + 1} {
+			a.PanicsWithValue(fmt.Sprintf("goavl: unknown quantile method %d", method), func() {
+				Quantiles(tree, []float64{0.5}, method)
+			})
+			a.Panics(func() {
+				tree.QuantileEntry(0.5, method)
+			})
+		}
+	}
+}
+
+func TestQuantilesRandom(t *testing.T) {
+	a := assert.New(t)
+	r := rand.New(rand.NewSource(3))
+	for _, countChildren := range []bool{false, true} {
+		tree := NewComparable[float64, int](WithCountChildren(countChildren))
+		var keys []float64
+		for i := 0; i < 1000; i++ {
+			k := r.NormFloat64()
+			if _, inserted := tree.Insert(k, i); inserted {
+				keys = append(keys, k)
+			}
+		}
+		sort.Float64s(keys)
+		qs := []float64{0.99, 0.5, 0.01, 0.5, 0.9, 0.1, 0.25, 0.75, 0.999}
+		got := Quantiles(tree, qs, QuantileLinear)
+		for i, q := range qs {
+			h := q * float64(len(keys)-1)
+			lo := int(h)
+			want := keys[lo]
+			if lo+1 < len(keys) {
+				want += (h - float64(lo)) * (keys[lo+1] - keys[lo])
+			}
+			a.InDeltaf(want, got[i], 1e-12, "q = %v", q)
+		}
+	}
+}
+
+func TestQuantileEntry(t *testing.T) {
+	for _, countChildren := range []bool{false, true} {
+		a := assert.New(t)
+		tree := NewComparable[string, int](WithCountChildren(countChildren))
+		_, found := tree.QuantileEntry(0.5, QuantileLower)
+		a.False(found)
+		a.Nil(tree.QuantileEntries([]float64{0.5}, QuantileLower))
+
+		for i, k := range []string{"d", "a", "c", "b"} {
+			tree.Insert(k, i)
+		}
+		for method, want := range map[QuantileMethod]string{
+			QuantileLower:   "b",
+			QuantileHigher:  "c",
+			QuantileNearest: "c",
+		} {
+			e, found := tree.QuantileEntry(0.5, method)
+			a.True(found)
+			a.Equalf(want, e.Key, "method %d", method)
+			v, _ := tree.Find(want)
+			a.Equal(v, e.Value)
+		}
+		var keys []string
+		for _, e := range tree.QuantileEntries([]float64{1, 0, 0.4, 0}, QuantileNearest) {
+			keys = append(keys, e.Key)
+		}
+		a.Equal([]string{"d", "a", "b", "a"}, keys)
+		for _, method := range []QuantileMethod{QuantileLinear, QuantileMidpoint} {
+			a.Panics(func() {
+				tree.QuantileEntry(0.5, method)
+			})
+		}
+		a.Panics(func() {
+			tree.QuantileEntry(2, QuantileLower)
+		})
+	}
+}
+
+func TestQuantileLargeIntegers(t *testing.T) {
+	a := assert.New(t)
+	const base = int64(1) << 60
+	tree := NewComparable[int64, struct{}](WithCountChildren(true))
+	for _, k := range []int64{base + 1, base + 3, base + 4, math.MinInt64, math.MaxInt64} {
+		tree.Insert(k, struct{}{})
+	}
+	e, _ := tree.QuantileEntry(0.5, QuantileLower)
+	a.Equal(base+3, e.Key)
+	e, _ = tree.QuantileEntry(0.25, QuantileNearest)
+	a.Equal(base+1, e.Key)
+	var keys []int64
+	for _, e := range tree.QuantileEntries([]float64{0, 1}, QuantileHigher) {
+		keys = append(keys, e.Key)
+	}
+	a.Equal([]int64{math.MinInt64, math.MaxInt64}, keys)
+
+	// float64 can't tell the keys apart, but QuantileEntry returns them exactly.
+	q, _ := Quantile(tree, 0.5, QuantileLower)
+	a.Equal(float64(base), q)
+	wide := NewComparable[int64, struct{}]()
+	wide.Insert(math.MinInt64, struct{}{})
+	wide.Insert(math.MaxInt64, struct{}{})
+	q, _ = Quantile(wide, 0.5, QuantileMidpoint)
+	a.Equal(0.0, q)
+}
@@ -0,0 +1,258 @@
+package goavl
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Interval is a half-open interval [Lo, Hi).
+type Interval[T constraints.Integer] struct {
+	Lo, Hi T
+}
+
+// Len returns the number of integers in the interval.
+func (i Interval[T]) Len() T {
+	return i.Hi - i.Lo
+}
+
+// String returns the interval in the form "[lo, hi)".
+func (i Interval[T]) String() string {
+	return fmt.Sprintf("[%d, %d)", i.Lo, i.Hi)
+}
+
+// RangeSet is a set of integers stored as disjoint half-open intervals.
+// Overlapping and adjacent intervals are merged on Add, so the set is always stored in its shortest form.
+// It's useful for tracking allocated IDs, received byte ranges and similar data.
+type RangeSet[T constraints.Integer] struct {
+	// t maps the start of every interval to its end.
+	// With the goavl_weights build tag the weight of an interval is its length, which makes CountBelow O(logn).
+	t *Tree[T, T, func(a, b T) int]
+	// total is the number of integers in the set.
+	total T
+}
+
+// NewRangeSet returns a new empty RangeSet.
+// Only allocator options are supported: WithSyncPool and WithArena.
+func NewRangeSet[T constraints.Integer](opts ...Option) *RangeSet[T] {
+	return newRangeSet[T](allocatorOptions(newOptions(opts)))
+}
+
+func newRangeSet[T constraints.Integer](options Options) *RangeSet[T] {
+	if weightsEnabled {
+		options.weight = intervalWeight[T]
+	}
+	return &RangeSet[T]{t: newWithOptions[T, T](compareIntegers[T], options)}
+}
+
+// allocatorOptions returns only the allocator options of o.
+func allocatorOptions(o Options) Options {
+	return Options{
+		at: o.at,
+		s:  o.s,
+		ao: o.ao,
+	}
+}
+
+func intervalWeight[T constraints.Integer](lo T, hi *T) uint64 {
+	return uint64(*hi - lo)
+}
+
+func compareIntegers[T constraints.Integer](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// Len returns the number of disjoint intervals.
+func (rs *RangeSet[T]) Len() int {
+	return rs.t.Len()
+}
+
+// Total returns the number of integers in the set. It must fit into T.
+// Time complexity: O(1).
+func (rs *RangeSet[T]) Total() T {
+	return rs.total
+}
+
+// CountBelow returns the number of integers in the set that are less than x.
+// Time complexity: O(logn) with the goavl_weights build tag, O(n) otherwise.
+func (rs *RangeSet[T]) CountBelow(x T) T {
+	if !weightsEnabled {
+		return rs.countBelowSlow(x)
+	}
+	result := rs.t.weightBefore(x, false)
+	if loc := rs.t.Floor(x).loc; !loc.isNil() && loc.key() < x && *loc.valuePtr() > x {
+		result -= uint64(*loc.valuePtr() - x)
+	}
+	return T(result)
+}
+
+// countBelowSlow returns the number of integers in the set that are less than x without using weights.
+// Time complexity: O(n).
+func (rs *RangeSet[T]) countBelowSlow(x T) T {
+	var result T
+	for loc := rs.t.min; !loc.isNil() && loc.key() < x; loc = nextLocation(loc) {
+		result += min2(*loc.valuePtr(), x) - loc.key()
+	}
+	return result
+}
+
+// Add adds the integers from [lo, hi) to the set. Does nothing, if lo >= hi.
+// Time complexity: O(logn + m*logn), where m is the number of merged intervals.
+func (rs *RangeSet[T]) Add(lo, hi T) {
+	if lo >= hi {
+		return
+	}
+	if loc := rs.t.Floor(lo).loc; !loc.isNil() && *loc.valuePtr() >= lo {
+		lo = loc.key()
+	}
+	for {
+		loc := rs.t.LowerBound(lo).loc
+		if loc.isNil() || loc.key() > hi {
+			break
+		}
+		start, end := loc.key(), *loc.valuePtr()
+		hi = max2(hi, end)
+		rs.t.Delete(start)
+		rs.total -= end - start
+	}
+	rs.t.Insert(lo, hi)
+	rs.total += hi - lo
+}
+
+// Remove removes the integers from [lo, hi) from the set. Does nothing, if lo >= hi.
+// An interval partially covered by [lo, hi) is shrunk or split in two.
+// Time complexity: O(logn + m*logn), where m is the number of affected intervals.
+func (rs *RangeSet[T]) Remove(lo, hi T) {
+	if lo >= hi {
+		return
+	}
+	if loc := rs.t.Floor(lo).loc; !loc.isNil() && loc.key() < lo && *loc.valuePtr() > lo {
+		start, end := loc.key(), *loc.valuePtr()
+		rs.t.Insert(start, lo)
+		rs.total -= end - lo
+		if end > hi {
+			rs.t.Insert(hi, end)
+			rs.total += end - hi
+			return
+		}
+	}
+	for {
+		loc := rs.t.LowerBound(lo).loc
+		if loc.isNil() || loc.key() >= hi {
+			break
+		}
+		start, end := loc.key(), *loc.valuePtr()
+		rs.t.Delete(start)
+		rs.total -= end - start
+		if end > hi {
+			rs.t.Insert(hi, end)
+			rs.total += end - hi
+		}
+	}
+}
+
+// Contains returns true if x is in the set.
+// Time complexity: O(logn).
+func (rs *RangeSet[T]) Contains(x T) bool {
+	loc := rs.t.Floor(x).loc
+	return !loc.isNil() && x < *loc.valuePtr()
+}
+
+// ContainsRange returns true if all the integers from [lo, hi) are in the set.
+// An empty range is always contained.
+// Time complexity: O(logn).
+func (rs *RangeSet[T]) ContainsRange(lo, hi T) bool {
+	if lo >= hi {
+		return true
+	}
+	loc := rs.t.Floor(lo).loc
+	return !loc.isNil() && hi <= *loc.valuePtr()
+}
+
+// overlaps returns true if any of the integers from [lo, hi) is in the set. lo must be less than hi.
+// Time complexity: O(logn).
+func (rs *RangeSet[T]) overlaps(lo, hi T) bool {
+	loc := rs.t.Floor(hi - 1).loc
+	return !loc.isNil() && *loc.valuePtr() > lo
+}
+
+// Gaps returns the intervals of [lo, hi) not covered by the set in ascending order.
+// Time complexity: O(logn + m), where m is the number of intervals intersecting [lo, hi).
+func (rs *RangeSet[T]) Gaps(lo, hi T) []Interval[T] {
+	var result []Interval[T]
+	if lo >= hi {
+		return result
+	}
+	cur := lo
+	loc := rs.t.Floor(lo).loc
+	if loc.isNil() {
+		loc = rs.t.min
+	}
+	for ; !loc.isNil() && loc.key() < hi; loc = nextLocation(loc) {
+		if loc.key() > cur {
+			result = append(result, Interval[T]{Lo: cur, Hi: loc.key()})
+		}
+		cur = max2(cur, *loc.valuePtr())
+	}
+	if cur < hi {
+		result = append(result, Interval[T]{Lo: cur, Hi: hi})
+	}
+	return result
+}
+
+// Intersect returns a new set of the integers present in both rs and other.
+// The new set uses the default allocator.
+// Time complexity: O(n + m), where n and m are the numbers of intervals in the sets.
+func (rs *RangeSet[T]) Intersect(other *RangeSet[T]) *RangeSet[T] {
+	result := NewRangeSet[T]()
+	a, b := rs.t.min, other.t.min
+	for !a.isNil() && !b.isNil() {
+		aEnd, bEnd := *a.valuePtr(), *b.valuePtr()
+		lo, hi := max2(a.key(), b.key()), min2(aEnd, bEnd)
+		if lo < hi {
+			// The intersections are disjoint and not adjacent, as the source intervals are.
+			result.t.Insert(lo, hi)
+			result.total += hi - lo
+		}
+		if aEnd < bEnd {
+			a = nextLocation(a)
+		} else {
+			b = nextLocation(b)
+		}
+	}
+	return result
+}
+
+// Each calls f for every interval of the set in ascending order, until f returns false.
+// The set must not be modified during the iteration.
+// Time complexity: O(n).
+func (rs *RangeSet[T]) Each(f func(i Interval[T]) bool) {
+	for loc := rs.t.min; !loc.isNil(); loc = nextLocation(loc) {
+		if !f(Interval[T]{Lo: loc.key(), Hi: *loc.valuePtr()}) {
+			return
+		}
+	}
+}
+
+// Intervals returns all the intervals of the set in ascending order.
+// Time complexity: O(n).
+func (rs *RangeSet[T]) Intervals() []Interval[T] {
+	result := make([]Interval[T], 0, rs.Len())
+	rs.Each(func(i Interval[T]) bool {
+		result = append(result, i)
+		return true
+	})
+	return result
+}
+
+// Clear removes all the intervals.
+func (rs *RangeSet[T]) Clear() {
+	rs.t.Clear()
+	rs.total = 0
+}
@@ -0,0 +1,11 @@
+//go:build go1.23
+
+package goavl
+
+import "iter"
+
+// All returns an iterator over the intervals of the set in ascending order.
+// It can be used in a for-range loop (Go 1.23+). See Each for the details.
+func (rs *RangeSet[T]) All() iter.Seq[Interval[T]] {
+	return rs.Each
+}
@@ -0,0 +1,175 @@
+package goavl
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func iv(lo, hi int) Interval[int] {
+	return Interval[int]{Lo: lo, Hi: hi}
+}
+
+func TestRangeSet(t *testing.T) {
+	a := assert.New(t)
+	rs := NewRangeSet[int]()
+	a.Empty(rs.Intervals())
+	a.False(rs.Contains(0))
+	a.Equal([]Interval[int]{iv(0, 10)}, rs.Gaps(0, 10))
+
+	rs.Add(10, 20)
+	rs.Add(30, 40)
+	rs.Add(5, 5)
+	a.Equal([]Interval[int]{iv(10, 20), iv(30, 40)}, rs.Intervals())
+	a.Equal(20, rs.Total())
+
+	rs.Add(20, 25)
+	a.Equal([]Interval[int]{iv(10, 25), iv(30, 40)}, rs.Intervals())
+	rs.Add(28, 30)
+	a.Equal([]Interval[int]{iv(10, 25), iv(28, 40)}, rs.Intervals())
+	rs.Add(12, 29)
+	a.Equal([]Interval[int]{iv(10, 40)}, rs.Intervals())
+	rs.Add(0, 100)
+	a.Equal([]Interval[int]{iv(0, 100)}, rs.Intervals())
+	a.Equal(100, rs.Total())
+
+	rs.Remove(10, 20)
+	rs.Remove(0, 5)
+	rs.Remove(90, 200)
+	a.Equal([]Interval[int]{iv(5, 10), iv(20, 90)}, rs.Intervals())
+	a.Equal(75, rs.Total())
+	rs.Remove(8, 30)
+	a.Equal([]Interval[int]{iv(5, 8), iv(30, 90)}, rs.Intervals())
+	a.Equal(63, rs.Total())
+
+	a.True(rs.Contains(5))
+	a.True(rs.Contains(7))
+	a.False(rs.Contains(8))
+	a.False(rs.Contains(4))
+	a.True(rs.ContainsRange(30, 90))
+	a.False(rs.ContainsRange(29, 31))
+	a.True(rs.ContainsRange(50, 50))
+	a.Equal(0, rs.CountBelow(5))
+	a.Equal(2, rs.CountBelow(7))
+	a.Equal(3, rs.CountBelow(30))
+	a.Equal(13, rs.CountBelow(40))
+	a.Equal(63, rs.CountBelow(1000))
+	a.Equal([]Interval[int]{iv(0, 5), iv(8, 30), iv(90, 100)}, rs.Gaps(0, 100))
+	a.Equal([]Interval[int]{iv(8, 10)}, rs.Gaps(6, 10))
+	a.Empty(rs.Gaps(40, 50))
+	a.Empty(rs.Gaps(50, 40))
+
+	other := NewRangeSet[int]()
+	other.Add(0, 6)
+	other.Add(7, 35)
+	other.Add(89, 95)
+	in := rs.Intersect(other)
+	a.Equal([]Interval[int]{iv(5, 6), iv(7, 8), iv(30, 35), iv(89, 90)}, in.Intervals())
+	a.Equal(8, in.Total())
+	a.Equal(in.Intervals(), other.Intersect(rs).Intervals())
+	a.Empty(rs.Intersect(NewRangeSet[int]()).Intervals())
+
+	var visited []Interval[int]
+	rs.Each(func(i Interval[int]) bool {
+		visited = append(visited, i)
+		return false
+	})
+	a.Equal([]Interval[int]{iv(5, 8)}, visited)
+	a.Equal("[5, 8)", visited[0].String())
+	a.Equal(3, visited[0].Len())
+
+	rs.Clear()
+	a.Zero(rs.Len())
+	a.Zero(rs.Total())
+}
+
+func TestRangeSetCounts(t *testing.T) {
+	a := assert.New(t)
+	// the counts are kept with or without the goavl_weights build tag.
+	a.Equal(weightsEnabled, NewRangeSet[int]().t.weight != nil)
+	rs := NewRangeSet[int]()
+	rs.Add(0, 10)
+	rs.Add(20, 25)
+	a.Equal(15, rs.Total())
+	a.Equal(12, rs.CountBelow(22))
+	rs.Remove(5, 22)
+	a.Equal(8, rs.Total())
+	a.Equal(5, rs.CountBelow(22))
+}
+
+func TestRangeSetRandom(t *testing.T) {
+	a := assert.New(t)
+	r := rand.New(rand.NewSource(13))
+	const size = 200
+	var model, otherModel [size]bool
+	rs, other := NewRangeSet[uint8](), NewRangeSet[uint8]()
+	for i := 0; i < 3000; i++ {
+		lo, hi := r.Intn(size), r.Intn(size)
+		switch r.Intn(5) {
+		case 0, 1:
+			rs.Add(uint8(lo), uint8(hi))
+			for x := lo; x < hi; x++ {
+				model[x] = true
+			}
+		case 2:
+			rs.Remove(uint8(lo), uint8(hi))
+			for x := lo; x < hi; x++ {
+				model[x] = false
+			}
+		case 3:
+			other.Add(uint8(lo), uint8(hi))
+			for x := lo; x < hi; x++ {
+				otherModel[x] = true
+			}
+		default:
+			other.Remove(uint8(lo), uint8(hi))
+			for x := lo; x < hi; x++ {
+				otherModel[x] = false
+			}
+		}
+		if i%30 != 0 {
+			continue
+		}
+		a.Equal(modelIntervals(model[:], 0, size, true), rs.Intervals())
+		a.Equal(modelIntervals(model[:], lo, hi, false), append([]Interval[uint8]{}, rs.Gaps(uint8(lo), uint8(hi))...))
+		var both [size]bool
+		var total uint8
+		for x := range model {
+			both[x] = model[x] && otherModel[x]
+			if model[x] {
+				total++
+			}
+			a.Equal(model[x], rs.Contains(uint8(x)))
+		}
+		a.Equal(total, rs.Total())
+		var below uint8
+		for x := 0; x < hi; x++ {
+			if model[x] {
+				below++
+			}
+		}
+		a.Equal(below, rs.CountBelow(uint8(hi)))
+		in := rs.Intersect(other)
+		a.Equal(modelIntervals(both[:], 0, size, true), in.Intervals())
+		a.NoError(in.t.Validate())
+		a.NoError(rs.t.Validate())
+	}
+}
+
+// modelIntervals returns the maximal intervals of [lo, hi), where set[x] == value.
+func modelIntervals(set []bool, lo, hi int, value bool) []Interval[uint8] {
+	result := []Interval[uint8]{}
+	for x := lo; x < hi; {
+		if set[x] != value {
+			x++
+			continue
+		}
+		start := x
+		for x < hi && set[x] == value {
+			x++
+		}
+		result = append(result, Interval[uint8]{Lo: uint8(start), Hi: uint8(x)})
+	}
+	return result
+}
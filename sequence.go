@@ -0,0 +1,162 @@
+package goavl
+
+// Sequence is an indexable list with O(logn) insertions and deletions at any position.
+// It's an AVL tree, where the elements are ordered by their positions, instead of keys.
+// It's useful for text buffers, playlists and other lists with frequent edits in the middle.
+type Sequence[V any] struct {
+	t *Tree[struct{}, V, func(a, b struct{}) int]
+}
+
+// NewSequence returns a new empty Sequence.
+// Only allocator options are supported: WithSyncPool and WithArena.
+// Children counts are always enabled.
+func NewSequence[V any](opts ...Option) *Sequence[V] {
+	options := allocatorOptions(newOptions(opts))
+	options.countChildren = true
+	return &Sequence[V]{t: newSequenceTree[V](options)}
+}
+
+// newSequenceTree returns a tree without a comparator, so that any key lookup panics.
+func newSequenceTree[V any](options Options) *Tree[struct{}, V, func(a, b struct{}) int] {
+	return newWithOptions[struct{}, V, func(a, b struct{}) int](nil, options)
+}
+
+// Len returns the number of elements.
+func (s *Sequence[V]) Len() int {
+	return s.t.length
+}
+
+// At returns the i'th element.
+// Panics with *OutOfRangeError if i is out of [0, Len()).
+// Time complexity: O(logn).
+func (s *Sequence[V]) At(i int) V {
+	return *s.t.locateAt(i).valuePtr()
+}
+
+// Set replaces the i'th element.
+// Panics with *OutOfRangeError if i is out of [0, Len()).
+// Time complexity: O(logn).
+func (s *Sequence[V]) Set(i int, v V) {
+	s.t.locateAt(i).setValue(v)
+}
+
+// InsertAt inserts v at position i, shifting the elements at positions >= i to the right.
+// Panics with *OutOfRangeError if i is out of [0, Len()].
+// Time complexity: O(logn).
+func (s *Sequence[V]) InsertAt(i int, v V) {
+	t := s.t
+	if i != t.length && !t.inRange(i) {
+		panic(&OutOfRangeError{Index: i, Len: t.length})
+	}
+	if !t.canAddCounted(1) {
+		panic(ErrCountOverflow)
+	}
+	var loc location[struct{}, V]
+	dir := dirCenter
+	switch {
+	case i == t.length:
+		if loc = t.max; !loc.isNil() {
+			dir = dirRight
+		}
+	default:
+		loc, dir = t.locateAt(i), dirLeft
+		if left := loc.left(); !left.isNil() {
+			loc, dir = goRight(left), dirRight
+		}
+	}
+	newNode := t.lc.new(struct{}{}, v)
+	newNode.setID(t.newLocationID())
+	t.insertLocation(loc, dir, newNode)
+}
+
+// Append inserts v at the end.
+// Time complexity: O(logn).
+func (s *Sequence[V]) Append(v V) {
+	s.InsertAt(s.t.length, v)
+}
+
+// DeleteAt deletes the i'th element and returns it.
+// Panics with *OutOfRangeError if i is out of [0, Len()).
+// Time complexity: O(logn).
+func (s *Sequence[V]) DeleteAt(i int) V {
+	_, v := s.t.DeleteAt(i)
+	return v
+}
+
+// Slice returns a copy of the elements at positions [i, j).
+// Panics with *OutOfRangeError if the range is invalid.
+// Time complexity: O(logn + j - i).
+func (s *Sequence[V]) Slice(i, j int) []V {
+	if i < 0 || i > j || j > s.t.length {
+		index := i
+		if i >= 0 && i <= j {
+			index = j
+		}
+		panic(&OutOfRangeError{Index: index, Len: s.t.length})
+	}
+	result := make([]V, 0, j-i)
+	if i == j {
+		return result
+	}
+	for loc := s.t.locateAt(i); len(result) < j-i; loc = nextLocation(loc) {
+		result = append(result, *loc.valuePtr())
+	}
+	return result
+}
+
+// Concat moves all the elements of other to the end of s. other becomes empty.
+// Both sequences should use the same allocator, as the nodes are moved, not copied.
+// Panics if other is s, or with ErrCountOverflow if the result is too long.
+// Time complexity: O(logn).
+func (s *Sequence[V]) Concat(other *Sequence[V]) {
+	if s == other {
+		panic("goavl: a sequence can't be concatenated with itself")
+	}
+	t, ot := s.t, other.t
+	if ot.length == 0 {
+		return
+	}
+	if t.length == 0 {
+		s.t, other.t = ot, t
+		return
+	}
+	if !t.canAddCounted(ot.length) {
+		panic(ErrCountOverflow)
+	}
+	// use the first element of other as the middle node of the join.
+	mid := ot.min
+	ot.detachAndReplace(mid)
+	length, oldMax := t.length+ot.length+1, ot.max
+	if oldMax.isNil() {
+		oldMax = mid
+	}
+	t.joinRoots(detachRoot(t.root), mid, detachRoot(ot.root))
+	t.length, t.max = length, oldMax
+	ot.Clear()
+}
+
+// SplitAt keeps the first i elements in s and returns a new sequence with the rest of them.
+// The new sequence shares the allocator with s.
+// Panics with *OutOfRangeError if i is out of [0, Len()].
+// Time complexity: O(logn).
+func (s *Sequence[V]) SplitAt(i int) *Sequence[V] {
+	t := s.t
+	if i != t.length && !t.inRange(i) {
+		panic(&OutOfRangeError{Index: i, Len: t.length})
+	}
+	rest := newSequenceTree[V](t.options)
+	rest.lc = t.lc
+	l, r := t.splitRoot(t.root, i)
+	rest.setRoot(r)
+	rest.length = t.length - i
+	rest.min, rest.max = goLeft(r), goRight(r)
+	t.setRoot(l)
+	t.length = i
+	t.min, t.max = goLeft(l), goRight(l)
+	return &Sequence[V]{t: rest}
+}
+
+// Validate checks the structure of the sequence, see Tree.Validate.
+func (s *Sequence[V]) Validate() error {
+	return s.t.Validate()
+}
@@ -0,0 +1,19 @@
+//go:build go1.23
+
+package goavl
+
+import "iter"
+
+// All returns an iterator over the positions and the elements of the sequence.
+// It can be used in a for-range loop (Go 1.23+).
+func (s *Sequence[V]) All() iter.Seq2[int, V] {
+	return func(yield func(int, V) bool) {
+		var i int
+		for loc := s.t.min; !loc.isNil(); loc = nextLocation(loc) {
+			if !yield(i, *loc.valuePtr()) {
+				break
+			}
+			i++
+		}
+	}
+}
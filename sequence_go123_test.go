@@ -0,0 +1,26 @@
+//go:build go1.23
+
+package goavl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequenceAllGo123(t *testing.T) {
+	a := assert.New(t)
+	s := NewSequence[string]()
+	for _, v := range []string{"b", "c", "a"} {
+		s.InsertAt(0, v)
+	}
+	var got []string
+	for i, v := range s.All() {
+		a.Equal(len(got), i)
+		got = append(got, v)
+		if i == 1 {
+			break
+		}
+	}
+	a.Equal([]string{"a", "c"}, got)
+}
@@ -0,0 +1,121 @@
+package goavl
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertSequence(t *testing.T, want []int, s *Sequence[int]) {
+	t.Helper()
+	a := assert.New(t)
+	a.NoError(s.Validate())
+	a.Equal(len(want), s.Len())
+	if len(want) == 0 {
+		want = []int{}
+	}
+	a.Equal(want, s.Slice(0, s.Len()))
+}
+
+func TestSequence(t *testing.T) {
+	a := assert.New(t)
+	s := NewSequence[int]()
+	assertSequence(t, nil, s)
+	s.Append(2)
+	s.InsertAt(0, 0)
+	s.InsertAt(1, 1)
+	s.Append(3)
+	assertSequence(t, []int{0, 1, 2, 3}, s)
+	a.Equal(2, s.At(2))
+	s.Set(2, 20)
+	a.Equal([]int{1, 20}, s.Slice(1, 3))
+	a.Equal(1, s.DeleteAt(1))
+	assertSequence(t, []int{0, 20, 3}, s)
+	a.Empty(s.Slice(3, 3))
+
+	for _, f := range []func(){
+		func() { s.At(3) },
+		func() { s.Set(-1, 0) },
+		func() { s.InsertAt(4, 0) },
+		func() { s.DeleteAt(3) },
+		func() { s.Slice(2, 4) },
+		func() { s.Slice(2, 1) },
+		func() { s.SplitAt(4) },
+	} {
+		func() {
+			defer func() {
+				err, _ := recover().(error)
+				a.True(errors.Is(err, ErrOutOfRange))
+			}()
+			f()
+		}()
+	}
+	a.Panics(func() { s.Concat(s) })
+}
+
+func TestSequenceConcatSplit(t *testing.T) {
+	a := assert.New(t)
+	for _, sizes := range [][2]int{{0, 0}, {0, 5}, {5, 0}, {1, 1}, {1, 100}, {100, 1}, {2, 1000}, {1000, 3}, {500, 500}} {
+		l, r := NewSequence[int](), NewSequence[int]()
+		var want []int
+		for i := 0; i < sizes[0]; i++ {
+			l.Append(i)
+			want = append(want, i)
+		}
+		for i := 0; i < sizes[1]; i++ {
+			r.Append(sizes[0] + i)
+			want = append(want, sizes[0]+i)
+		}
+		l.Concat(r)
+		assertSequence(t, want, l)
+		assertSequence(t, nil, r)
+		r.Append(-1)
+		a.Equal(1, r.Len())
+
+		for _, at := range []int{0, len(want) / 3, len(want)} {
+			rest := l.SplitAt(at)
+			assertSequence(t, want[:at], l)
+			assertSequence(t, want[at:], rest)
+			l.Concat(rest)
+			assertSequence(t, want, l)
+		}
+	}
+}
+
+func TestSequenceRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	s := NewSequence[int](WithSyncPool(nil))
+	var want []int
+	for i := 0; i < 3000; i++ {
+		switch op := r.Intn(10); {
+		case op < 5 || len(want) == 0:
+			pos := r.Intn(len(want) + 1)
+			s.InsertAt(pos, i)
+			want = append(want[:pos], append([]int{i}, want[pos:]...)...)
+		case op < 7:
+			pos := r.Intn(len(want))
+			assert.Equal(t, want[pos], s.DeleteAt(pos))
+			want = append(want[:pos], want[pos+1:]...)
+		case op < 8:
+			pos := r.Intn(len(want))
+			s.Set(pos, -i)
+			want[pos] = -i
+		default:
+			pos := r.Intn(len(want) + 1)
+			rest := s.SplitAt(pos)
+			if err := rest.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			s.Concat(rest)
+		}
+		if err := s.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if i%100 == 0 {
+			assertSequence(t, want, s)
+		}
+	}
+	assertSequence(t, want, s)
+}
@@ -2,6 +2,7 @@ package goavl
 
 import (
 	"sync"
+	"time"
 
 	"golang.org/x/exp/constraints"
 )
@@ -22,6 +23,42 @@ type Options struct {
 	s *sync.Pool
 
 	ao arenaOptions
+
+	// jsonFormat defines how the tree is encoded to JSON.
+	jsonFormat JSONFormat
+
+	// journal is a *journal[K, V] set by WithJournal.
+	journal any
+
+	// observers are Observer[K, V] values set by WithObserver.
+	observers []any
+
+	// clock returns current time for time-aware types.
+	clock func() time.Time
+
+	// after waits for a duration for time-aware types.
+	after func(d time.Duration) <-chan time.Time
+
+	// ttl is the default time-to-live for ExpiringTree entries.
+	ttl time.Duration
+
+	// capacity is the maximum number of elements, if positive.
+	capacity int
+
+	// evictPolicy defines which element is evicted when the capacity is reached.
+	evictPolicy EvictPolicy
+
+	// onEvict is a func(k K, v V) set by WithEvictionCallback.
+	onEvict any
+
+	// paranoid enables validation of the tree after every mutation.
+	paranoid bool
+
+	// checkCmp enables consistency checks of the comparator's answers.
+	checkCmp bool
+
+	// weight is a func(k K, v *V) uint64 set by WithWeights.
+	weight any
 }
 
 const (
@@ -70,6 +107,10 @@ type Tree[K, V any, Cmp func(a, b K) int] struct {
 	nextID         uint64
 	cmp            Cmp
 	lc             locationCache[K, V]
+	journal        *journal[K, V]
+	observers      []Observer[K, V]
+	onEvict        func(k K, v V)
+	weight         func(k K, v *V) uint64
 }
 
 // New returns a new Tree.
@@ -93,15 +134,32 @@ type Tree[K, V any, Cmp func(a, b K) int] struct {
 //	}
 //
 // tree := New[int, int](intCmp, WithCountChildren(true)).
+//
+// New panics if WithClock, WithAfter or WithTTL is used.
 func New[K, V any, Cmp func(a, b K) int](cmp Cmp, opts ...Option) *Tree[K, V, Cmp] {
-	result := &Tree[K, V, Cmp]{
-		cmp: cmp,
-		options: Options{
-			countChildren: false,
-		},
+	return newWithOptions[K, V](cmp, newOptions(opts))
+}
+
+func newOptions(opts []Option) Options {
+	options := Options{
+		countChildren: false,
 	}
 	for _, o := range opts {
-		o(&result.options)
+		o(&options)
+	}
+	return options
+}
+
+func newWithOptions[K, V any, Cmp func(a, b K) int](cmp Cmp, options Options) *Tree[K, V, Cmp] {
+	if options.clock != nil || options.after != nil || options.ttl != 0 {
+		panic("goavl: WithClock, WithAfter and WithTTL are only supported by ExpiringTree and DelayQueue")
+	}
+	result := &Tree[K, V, Cmp]{
+		cmp:     cmp,
+		options: options,
+	}
+	if options.checkCmp {
+		result.cmp = checkedComparator[K](cmp)
 	}
 	switch result.options.at {
 	case allocBasic:
@@ -111,6 +169,10 @@ func New[K, V any, Cmp func(a, b K) int](cmp Cmp, opts ...Option) *Tree[K, V, Cm
 	case allocArenas:
 		result.lc = newArenaLocationCache[K, V](result.options.ao)
 	}
+	result.journal = newJournal[K, V](result.options.journal)
+	result.observers = newObservers[K, V](result.options.observers)
+	result.onEvict = newEvictionCallback[K, V](result.options.onEvict)
+	result.weight = newWeightFunc[K, V](result.options.weight)
 	return result
 }
 
@@ -132,21 +194,49 @@ func NewComparable[K constraints.Ordered, V any](opts ...Option) *Tree[K, V, fun
 // Insert inserts a node into the tree.
 // Returns a pointer to the value and true, if a new node was added.
 // If the key `k` was present in the tree, node's value is updated to `v`.
+// If the tree has a capacity set by WithCapacity and is full, either an element is evicted,
+// or, if `k` itself would be evicted, the insertion is rejected and valuePtr is nil.
+// Panics with ErrCountOverflow if the tree has children counts and can't hold more elements.
 // Time complexity: O(logn).
 func (t *Tree[K, V, Cmp]) Insert(k K, v V) (valuePtr *V, inserted bool) {
 	loc, dir := t.locate(k)
 	if dir == dirCenter && !loc.isNil() {
+		var oldValue V
+		notify := len(t.observers) > 0
+		if notify {
+			oldValue = *loc.valuePtr()
+		}
 		loc.setValue(v)
+		t.reweigh(loc)
+		t.journal.insert(k, v)
+		if notify {
+			t.notifyUpdate(k, oldValue, v)
+		}
 		return loc.valuePtr(), false
 	}
+	if t.options.capacity > 0 && t.length >= t.options.capacity {
+		if !t.evictFor(k) {
+			return nil, false
+		}
+		loc, dir = t.locate(k)
+	}
+	if !t.canAddCounted(1) {
+		panic(ErrCountOverflow)
+	}
 	newNode := t.lc.new(k, v)
 	newNode.setID(t.newLocationID())
 	t.insertLocation(loc, dir, newNode)
+	t.checkInvariants()
+	t.journal.insert(k, v)
+	t.notifyInsert(k, v)
 	return newNode.valuePtr(), true
 }
 
 func (t *Tree[K, V, Cmp]) insertLocation(loc location[K, V], dir direction, newNode location[K, V]) {
 	t.length++
+	if t.weight != nil {
+		newNode.recalcWeight(t.weight)
+	}
 	switch dir {
 	case dirLeft, dirRight:
 		loc.addChild(newNode, dir)
@@ -156,9 +246,7 @@ func (t *Tree[K, V, Cmp]) insertLocation(loc location[K, V], dir direction, newN
 			t.max = newNode
 		}
 		if loc.recalcHeight() {
-			if t.options.countChildren {
-				loc.recalcCounts()
-			}
+			t.recalcAugments(loc)
 			t.checkBalance(loc.parent(), false)
 		} else {
 			t.updateCounts(loc)
@@ -169,16 +257,27 @@ func (t *Tree[K, V, Cmp]) insertLocation(loc location[K, V], dir direction, newN
 	}
 }
 
+// updateCounts recalculates children counts and weights from loc up to the root.
 func (t *Tree[K, V, Cmp]) updateCounts(loc location[K, V]) {
-	if !t.options.countChildren {
+	if !t.options.countChildren && t.weight == nil {
 		return
 	}
 	for !loc.isNil() {
-		loc.recalcCounts()
+		t.recalcAugments(loc)
 		loc = loc.parent()
 	}
 }
 
+// recalcAugments recalculates children counts and weights of loc, if enabled.
+func (t *Tree[K, V, Cmp]) recalcAugments(loc location[K, V]) {
+	if t.options.countChildren {
+		loc.recalcCounts()
+	}
+	if t.weight != nil {
+		loc.recalcWeight(t.weight)
+	}
+}
+
 // Entry is a pair of a key and a pointer to the value.
 type Entry[K, V any] struct {
 	Key   K
@@ -217,8 +316,30 @@ func (t *Tree[K, V, Cmp]) Max() (entry Entry[K, V], found bool) {
 	return entry, found
 }
 
+// PopMin deletes the minimum of the tree and returns it.
+// If the tree is empty, `found` value will be false.
+// Time complexity: O(logn), the minimum is located in O(1).
+func (t *Tree[K, V, Cmp]) PopMin() (k K, v V, found bool) {
+	if t.min.isNil() {
+		return k, v, false
+	}
+	k, v = t.deleteLocation(t.min)
+	return k, v, true
+}
+
+// PopMax deletes the maximum of the tree and returns it.
+// If the tree is empty, `found` value will be false.
+// Time complexity: O(logn), the maximum is located in O(1).
+func (t *Tree[K, V, Cmp]) PopMax() (k K, v V, found bool) {
+	if t.max.isNil() {
+		return k, v, false
+	}
+	k, v = t.deleteLocation(t.max)
+	return k, v, true
+}
+
 // At returns a (key, value) pair at the ith position of the sorted array.
-// Panics if position >= tree.Len().
+// Panics with *OutOfRangeError if position is out of [0, tree.Len()).
 // Time complexity:
 //
 //	O(logn) - if children node counts are enabled.
@@ -407,8 +528,8 @@ func (t *Tree[K, V, Cmp]) shouldLocateAtLinearly(position int) bool {
 }
 
 func (t *Tree[K, V, Cmp]) locateAt(position int) location[K, V] {
-	if position < 0 || position >= t.Len() {
-		panic("index out of range")
+	if !t.inRange(position) {
+		panic(&OutOfRangeError{Index: position, Len: t.length})
 	}
 	if !t.options.countChildren || t.shouldLocateAtLinearly(position) {
 		if position < t.length/2 {
@@ -448,7 +569,7 @@ func (t *Tree[K, V, Cmp]) iteratorAt(loc location[K, V]) Iterator[K, V, Cmp] {
 }
 
 // IteratorAt returns an iterator pointing to the i'th element.
-// Panics if position >= tree.Len().
+// Panics with *OutOfRangeError if position is out of [0, tree.Len()).
 // Time complexity:
 //
 //	O(logn) - if children node counts are enabled.
@@ -473,11 +594,20 @@ func (t *Tree[K, V, Cmp]) Delete(k K) (v V, deleted bool) {
 	if dir != dirCenter || loc.isNil() {
 		return v, false
 	}
-	v = *loc.valuePtr()
-	t.deleteAndReplace(loc)
+	_, v = t.deleteLocation(loc)
 	return v, true
 }
 
+// deleteLocation deletes loc from the tree and reports the deletion to the journal and observers.
+func (t *Tree[K, V, Cmp]) deleteLocation(loc location[K, V]) (k K, v V) {
+	k, v = loc.key(), *loc.valuePtr()
+	t.deleteAndReplace(loc)
+	t.checkInvariants()
+	t.journal.delete(k)
+	t.notifyDelete(k, v)
+	return k, v
+}
+
 func (t *Tree[K, V, Cmp]) canUpdateKeyInPlace(loc location[K, V], newKey K) bool {
 	if prev := prevLocation(loc); !prev.isNil() && t.cmp(prev.key(), newKey) >= 0 {
 		return false
@@ -497,33 +627,51 @@ func (t *Tree[K, V, Cmp]) resetDetachedLocation(loc location[K, V], k K, v V) {
 // Returns a pointer to the final value and true if oldKey was present.
 // Time complexity: O(logn).
 func (t *Tree[K, V, Cmp]) UpdateKey(oldKey K, newKey K) (valuePtr *V, updated bool) {
+	valuePtr, updated, replaced, wasReplaced := t.updateKey(oldKey, newKey)
+	if updated {
+		t.checkInvariants()
+		t.journal.updateKey(oldKey, newKey)
+		if wasReplaced {
+			t.notifyDelete(newKey, replaced)
+		}
+		t.notifyKeyChange(oldKey, newKey, *valuePtr)
+	}
+	return valuePtr, updated
+}
+
+// updateKey returns the value of newKey that was replaced, if any.
+func (t *Tree[K, V, Cmp]) updateKey(oldKey K, newKey K) (valuePtr *V, updated bool, replaced V, wasReplaced bool) {
 	oldLoc, oldDir := t.locate(oldKey)
 	if oldDir != dirCenter || oldLoc.isNil() {
-		return nil, false
+		return nil, false, replaced, false
 	}
 	if t.cmp(oldLoc.key(), newKey) == 0 {
 		oldLoc.k = newKey
-		return oldLoc.valuePtr(), true
+		t.reweigh(oldLoc)
+		return oldLoc.valuePtr(), true, replaced, false
 	}
 
 	newLoc, newDir := t.locate(newKey)
 	if newDir == dirCenter && !newLoc.isNil() {
 		oldValue := *oldLoc.valuePtr()
+		replaced = *newLoc.valuePtr()
 		newLoc.setValue(oldValue)
 		t.deleteAndReplace(oldLoc)
-		return newLoc.valuePtr(), true
+		t.reweigh(newLoc)
+		return newLoc.valuePtr(), true, replaced, true
 	}
 
 	if t.canUpdateKeyInPlace(oldLoc, newKey) {
 		oldLoc.k = newKey
-		return oldLoc.valuePtr(), true
+		t.reweigh(oldLoc)
+		return oldLoc.valuePtr(), true, replaced, false
 	}
 
 	oldValue := *oldLoc.valuePtr()
 	t.detachAndReplace(oldLoc)
 	t.resetDetachedLocation(oldLoc, newKey, oldValue)
 	t.insertLocation(newLoc, newDir, oldLoc)
-	return oldLoc.valuePtr(), true
+	return oldLoc.valuePtr(), true, replaced, false
 }
 
 // DeleteIterator deletes the element referenced by the iterator.
@@ -534,7 +682,7 @@ func (t *Tree[K, V, Cmp]) DeleteIterator(it Iterator[K, V, Cmp]) Iterator[K, V,
 		return Iterator[K, V, Cmp]{}
 	}
 	next := nextLocation(it.loc)
-	t.deleteAndReplace(it.loc)
+	t.deleteLocation(it.loc)
 	return t.iteratorAt(next)
 }
 
@@ -552,17 +700,13 @@ func (t *Tree[K, V, Cmp]) isValidloc(loc location[K, V], id uint64) bool {
 }
 
 // DeleteAt deletes a node at the given position.
-// Returns node's value. Panics if position >= tree.Len().
+// Returns node's value. Panics with *OutOfRangeError if position is out of [0, tree.Len()).
 // Time complexity:
 //
 //	O(logn) - if children node counts are enabled.
 //	O(n) - otherwise.
 func (t *Tree[K, V, Cmp]) DeleteAt(position int) (k K, v V) {
-	loc := t.locateAt(position)
-	k = loc.key()
-	v = *loc.valuePtr()
-	t.deleteAndReplace(loc)
-	return k, v
+	return t.deleteLocation(t.locateAt(position))
 }
 
 func (t *Tree[K, V, Cmp]) findReplacement(loc location[K, V]) location[K, V] {
@@ -669,14 +813,22 @@ func (t *Tree[K, V, Cmp]) setRoot(root location[K, V]) {
 	}
 }
 
-// Clear clears the tree in O(1) time.
+// Clear clears the tree in O(1) time, or in O(n) time, if the tree has observers,
+// which are notified about every deleted element in ascending order.
 // Allocated nodes are not returned to the allocator. Delete elements explicitly
 // if you want allocator-specific release behavior, such as sync.Pool reuse.
 func (t *Tree[K, V, Cmp]) Clear() {
+	first := t.min
 	t.root = location[K, V]{}
 	t.min = t.root
 	t.max = t.root
 	t.length = 0
+	t.journal.clear()
+	if len(t.observers) > 0 {
+		for loc := first; !loc.isNil(); loc = nextLocation(loc) {
+			t.notifyDelete(loc.key(), *loc.valuePtr())
+		}
+	}
 }
 
 // Len returns the number of elements.
@@ -801,6 +953,14 @@ func (t *Tree[K, V, Cmp]) locate(k K) (loc location[K, V], dir direction) {
 }
 
 func (t *Tree[K, V, Cmp]) treeRotated(parent, oldRoot, newRoot location[K, V]) {
+	if t.weight != nil {
+		// the children of the new root are the only nodes, whose subtrees changed.
+		for _, child := range [...]location[K, V]{newRoot.left(), newRoot.right(), newRoot} {
+			if !child.isNil() {
+				child.recalcWeight(t.weight)
+			}
+		}
+	}
 	if !parent.isNil() {
 		parent.setChild(newRoot, parent.childDir(oldRoot))
 	} else {
@@ -837,9 +997,7 @@ func (t *Tree[K, V, Cmp]) checkBalance(loc location[K, V], fullWayUp bool) {
 				t.updateCounts(loc)
 				return
 			}
-			if t.options.countChildren {
-				loc.recalcCounts()
-			}
+			t.recalcAugments(loc)
 		}
 		loc = parent
 	}
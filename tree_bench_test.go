@@ -205,3 +205,21 @@ func shuffledInts(n int) []int {
 	})
 	return result
 }
+
+func BenchmarkParallelBuild_1(b *testing.B) {
+	benchmarkParallelBuild(b, 1)
+}
+
+func BenchmarkParallelBuild_GOMAXPROCS(b *testing.B) {
+	benchmarkParallelBuild(b, 0)
+}
+
+func benchmarkParallelBuild(b *testing.B, parallelism int) {
+	keys, values := sortedInts(1 << 20)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParallelBuild(intCmp, keys, values, parallelism, WithCountChildren(true)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
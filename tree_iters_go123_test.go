@@ -69,3 +69,22 @@ func TestTreeMutIteratorGo123(t *testing.T) {
 	a.Equal(128, i)
 	a.Zero(tree.Len())
 }
+
+func TestTreeMutIteratorObserverGo123(t *testing.T) {
+	a := assert.New(t)
+	var deleted []int
+	tree := NewComparable[int, int](WithObserver[int, int](ObserverFuncs[int, int]{
+		Delete: func(k, v int) {
+			deleted = append(deleted, k)
+		},
+	}))
+	for i := range 8 {
+		tree.Insert(i, i)
+	}
+	for m := range tree.AllMut() {
+		if m.E.Key%2 == 0 {
+			m.Delete()
+		}
+	}
+	a.Equal([]int{0, 2, 4, 6}, deleted)
+}
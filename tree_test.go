@@ -7,6 +7,7 @@ import (
 	"os"
 	"sync"
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -21,6 +22,38 @@ func intCmp(a, b int) int {
 	return 0
 }
 
+func intWeight(_ int, v *int) uint64 {
+	return uint64(*v)
+}
+
+// withTestWeights returns WithWeights(intWeight), or an option that does nothing,
+// if the package is built without the goavl_weights tag.
+func withTestWeights() Option {
+	if !weightsEnabled {
+		return func(*Options) {}
+	}
+	return WithWeights(intWeight)
+}
+
+func TestNodeWeight(t *testing.T) {
+	a := assert.New(t)
+	var n node[int64, int64]
+	var unweighted struct {
+		k, v   int64
+		h      uint8
+		nchild childCount
+	}
+	a.Equal(unsafe.Sizeof(unweighted)+unsafe.Sizeof(n.nodeWeight), unsafe.Sizeof(n))
+	if weightsEnabled {
+		a.Equal(unsafe.Sizeof(uint64(0)), unsafe.Sizeof(n.nodeWeight))
+		return
+	}
+	a.Zero(unsafe.Sizeof(n.nodeWeight))
+	a.Panics(func() {
+		NewComparable[int, int](WithWeights(intWeight))
+	})
+}
+
 func TestEmptyTree(t *testing.T) {
 	a := assert.New(t)
 	tree := NewComparable[int, int](WithCountChildren(true))
@@ -1015,7 +1048,7 @@ func checkHeightAndBalance[K, V any](l location[K, V], checkCounts bool) error {
 	return err
 }
 
-func recalcHeightAndBalance[K, V any](l location[K, V], checkCounts bool) (height uint8, lCount, rCount uint32, err error) {
+func recalcHeightAndBalance[K, V any](l location[K, V], checkCounts bool) (height uint8, lCount, rCount childCount, err error) {
 	if l.isNil() {
 		return 0, 0, 0, nil
 	}
@@ -0,0 +1,128 @@
+package goavl
+
+import "errors"
+
+// ErrTxDone is returned by Commit and Rollback if the transaction has already been finished.
+var ErrTxDone = errors.New("goavl: transaction has already been committed or rolled back")
+
+const (
+	undoDelete = iota + 1
+	undoInsert
+	undoUpdateKey
+)
+
+type undoRecord[K, V any] struct {
+	op    int8
+	k, k2 K
+	v     V
+}
+
+// Tx is a transaction over a tree.
+// Every mutation of the tree made while the transaction is open becomes a part of it,
+// no matter whether it's made via Tx or directly via the tree, its iterators or mutators.
+// Rollback restores the contents of the tree using an undo log.
+// Clear is logged as the deletion of every element, so it takes O(n) while a transaction is open.
+// The modifications made via value pointers can't be rolled back.
+// Tx is not safe for concurrent use.
+type Tx[K, V any, Cmp func(a, b K) int] struct {
+	t    *Tree[K, V, Cmp]
+	log  *undoLog[K, V]
+	done bool
+}
+
+// Begin starts a new transaction.
+// Transactions can be nested: rolling back an outer transaction also reverts
+// the changes of the committed inner ones.
+func (t *Tree[K, V, Cmp]) Begin() *Tx[K, V, Cmp] {
+	tx := &Tx[K, V, Cmp]{t: t, log: &undoLog[K, V]{}}
+	t.addObserver(tx.log)
+	return tx
+}
+
+// Tree returns the tree the transaction belongs to.
+func (tx *Tx[K, V, Cmp]) Tree() *Tree[K, V, Cmp] {
+	return tx.t
+}
+
+// Insert inserts a key into the tree. See Tree.Insert.
+func (tx *Tx[K, V, Cmp]) Insert(k K, v V) (valuePtr *V, inserted bool) {
+	return tx.t.Insert(k, v)
+}
+
+// Delete deletes a key from the tree. See Tree.Delete.
+func (tx *Tx[K, V, Cmp]) Delete(k K) (v V, deleted bool) {
+	return tx.t.Delete(k)
+}
+
+// DeleteAt deletes a node at the given position. See Tree.DeleteAt.
+func (tx *Tx[K, V, Cmp]) DeleteAt(position int) (k K, v V) {
+	return tx.t.DeleteAt(position)
+}
+
+// DeleteIterator deletes the element referenced by the iterator. See Tree.DeleteIterator.
+func (tx *Tx[K, V, Cmp]) DeleteIterator(it Iterator[K, V, Cmp]) Iterator[K, V, Cmp] {
+	return tx.t.DeleteIterator(it)
+}
+
+// UpdateKey changes a node key while preserving its value. See Tree.UpdateKey.
+func (tx *Tx[K, V, Cmp]) UpdateKey(oldKey K, newKey K) (valuePtr *V, updated bool) {
+	return tx.t.UpdateKey(oldKey, newKey)
+}
+
+// Commit keeps the changes and discards the undo log.
+func (tx *Tx[K, V, Cmp]) Commit() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.finish()
+	return nil
+}
+
+// Rollback reverts all the changes made since Begin.
+// Time complexity: O(mlogn), where m is the number of mutations.
+func (tx *Tx[K, V, Cmp]) Rollback() error {
+	if tx.done {
+		return ErrTxDone
+	}
+	tx.finish()
+	records := tx.log.records
+	for i := len(records) - 1; i >= 0; i-- {
+		r := &records[i]
+		switch r.op {
+		case undoDelete:
+			tx.t.Delete(r.k)
+		case undoInsert:
+			tx.t.Insert(r.k, r.v)
+		case undoUpdateKey:
+			tx.t.UpdateKey(r.k, r.k2)
+		}
+	}
+	tx.log.records = nil
+	return nil
+}
+
+func (tx *Tx[K, V, Cmp]) finish() {
+	tx.done = true
+	tx.t.removeObserver(tx.log)
+}
+
+// undoLog is an Observer that records the operations reverting the observed mutations.
+type undoLog[K, V any] struct {
+	records []undoRecord[K, V]
+}
+
+func (l *undoLog[K, V]) OnInsert(k K, _ V) {
+	l.records = append(l.records, undoRecord[K, V]{op: undoDelete, k: k})
+}
+
+func (l *undoLog[K, V]) OnUpdate(k K, oldValue, _ V) {
+	l.records = append(l.records, undoRecord[K, V]{op: undoInsert, k: k, v: oldValue})
+}
+
+func (l *undoLog[K, V]) OnDelete(k K, v V) {
+	l.records = append(l.records, undoRecord[K, V]{op: undoInsert, k: k, v: v})
+}
+
+func (l *undoLog[K, V]) OnKeyChange(oldKey, newKey K, _ V) {
+	l.records = append(l.records, undoRecord[K, V]{op: undoUpdateKey, k: newKey, k2: oldKey})
+}
@@ -0,0 +1,136 @@
+package goavl
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func treeSnapshot[K, V any, Cmp func(a, b K) int](tree *Tree[K, V, Cmp]) []jsonEntry[K, V] {
+	var result []jsonEntry[K, V]
+	it := tree.IteratorAtFirst()
+	for e, ok := it.Next(); ok; e, ok = it.Next() {
+		result = append(result, jsonEntry[K, V]{Key: e.Key, Value: *e.Value})
+	}
+	return result
+}
+
+func TestTxRollback(t *testing.T) {
+	for _, countChildren := range []bool{false, true} {
+		a := assert.New(t)
+		tree := NewComparable[int, int](WithCountChildren(countChildren))
+		for i := 0; i < 10; i++ {
+			tree.Insert(i, i)
+		}
+		before := treeSnapshot(tree)
+
+		tx := tree.Begin()
+		tx.Insert(100, 100)
+		tx.Insert(5, 50)
+		tx.Delete(3)
+		tx.DeleteAt(0)
+		tx.DeleteIterator(tx.Tree().IteratorAtLast())
+		tx.UpdateKey(4, 40)
+		tx.UpdateKey(40, 6)
+		tx.UpdateKey(7, 7)
+		tree.Insert(-1, -1)
+		a.NotEqual(before, treeSnapshot(tree))
+
+		a.NoError(tx.Rollback())
+		a.Equal(before, treeSnapshot(tree))
+		a.NoError(checkHeightAndBalance(tree.root, countChildren))
+		for i := 0; i < 10; i++ {
+			a.Equal(i, tree.At(i).Key)
+			rank, found := tree.Rank(i)
+			a.True(found)
+			a.Equal(i, rank)
+		}
+		a.True(errors.Is(tx.Rollback(), ErrTxDone))
+		a.True(errors.Is(tx.Commit(), ErrTxDone))
+		a.Empty(tree.observers)
+	}
+}
+
+func TestTxCommit(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int](WithCountChildren(true))
+	tx := tree.Begin()
+	tx.Insert(1, 1)
+	tx.Insert(2, 2)
+	a.NoError(tx.Commit())
+	tree.Insert(3, 3)
+	a.True(errors.Is(tx.Rollback(), ErrTxDone))
+	assertTreeKeys(t, tree, []int{1, 2, 3})
+	a.Empty(tree.observers)
+}
+
+func TestTxRollbackClear(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int](WithCountChildren(true))
+	for i := 0; i < 10; i++ {
+		tree.Insert(i, i*10)
+	}
+	before := treeSnapshot(tree)
+	tx := tree.Begin()
+	tree.Clear()
+	a.Zero(tree.Len())
+	tx.Insert(20, 20)
+	a.NoError(tx.Rollback())
+	a.Equal(before, treeSnapshot(tree))
+	a.NoError(tree.Validate())
+	a.Empty(tree.observers)
+}
+
+func TestTxNested(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int](WithCountChildren(true))
+	tree.Insert(1, 1)
+	outer := tree.Begin()
+	outer.Insert(2, 2)
+	inner := tree.Begin()
+	inner.Insert(3, 3)
+	inner.Delete(1)
+	a.NoError(inner.Commit())
+	inner = tree.Begin()
+	inner.Insert(4, 4)
+	a.NoError(inner.Rollback())
+	assertTreeKeys(t, tree, []int{2, 3})
+	a.NoError(outer.Rollback())
+	assertTreeKeys(t, tree, []int{1})
+}
+
+func TestTxRollbackRandom(t *testing.T) {
+	a := assert.New(t)
+	r := rand.New(rand.NewSource(42))
+	tree := NewComparable[int, int](WithCountChildren(true))
+	for i := 0; i < 256; i++ {
+		tree.Insert(r.Intn(512), r.Int())
+	}
+	for round := 0; round < 20; round++ {
+		before := treeSnapshot(tree)
+		tx := tree.Begin()
+		for i := 0; i < 100; i++ {
+			switch r.Intn(4) {
+			case 0:
+				tx.Insert(r.Intn(512), r.Int())
+			case 1:
+				tx.Delete(r.Intn(512))
+			case 2:
+				if tree.Len() > 0 {
+					tx.DeleteAt(r.Intn(tree.Len()))
+				}
+			case 3:
+				tx.UpdateKey(r.Intn(512), r.Intn(512))
+			}
+		}
+		if round%2 == 0 {
+			a.NoError(tx.Rollback())
+			a.Equal(before, treeSnapshot(tree))
+		} else {
+			a.NoError(tx.Commit())
+		}
+		a.NoError(checkHeightAndBalance(tree.root, true))
+	}
+}
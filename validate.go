@@ -0,0 +1,112 @@
+package goavl
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidTree is wrapped by the errors returned from Validate.
+var ErrInvalidTree = errors.New("goavl: invalid tree")
+
+// WithParanoidChecks makes the tree call Validate after every structural mutation
+// and panic with the returned error, if any.
+// It helps to find comparator bugs close to where they corrupt the tree,
+// but makes every mutation O(n), so it's only intended for debugging.
+func WithParanoidChecks(enabled bool) Option {
+	return func(o *Options) {
+		o.paranoid = enabled
+	}
+}
+
+// Validate checks the invariants of the tree:
+//   - keys are in strictly ascending order according to the comparator;
+//   - heights are correct and the heights of the subtrees of every node differ by at most one;
+//   - children point to their parents, and the root has no parent;
+//   - children counts are correct, if WithCountChildren is enabled;
+//   - subtree weights are correct, if WithWeights is enabled;
+//   - Min, Max and Len are consistent with the nodes.
+//
+// The returned error wraps ErrInvalidTree and describes the violated invariant and the node.
+// Time complexity: O(n).
+func (t *Tree[K, V, Cmp]) Validate() error {
+	if !t.root.isNil() && !t.root.parent().isNil() {
+		return t.invalid("the root has a parent", t.root)
+	}
+	var prev location[K, V]
+	_, count, err := t.validateLocation(t.root, &prev)
+	if err != nil {
+		return err
+	}
+	if count != t.length {
+		return fmt.Errorf("%w: length is %d, but there are %d nodes", ErrInvalidTree, t.length, count)
+	}
+	if leftmost := goLeft(t.root); leftmost != t.min {
+		return t.invalid("min doesn't point to the leftmost node", t.min)
+	}
+	if rightmost := goRight(t.root); rightmost != t.max {
+		return t.invalid("max doesn't point to the rightmost node", t.max)
+	}
+	return nil
+}
+
+// validateLocation validates the subtree rooted at loc in order, keeping the previous node in prev.
+// Returns the height and the number of nodes of the subtree.
+func (t *Tree[K, V, Cmp]) validateLocation(loc location[K, V], prev *location[K, V]) (height int, count int, err error) {
+	if loc.isNil() {
+		return -1, 0, nil
+	}
+	left, right := loc.left(), loc.right()
+	if !left.isNil() && left.parent() != loc {
+		return 0, 0, t.invalid("the left child has a wrong parent", left)
+	}
+	if !right.isNil() && right.parent() != loc {
+		return 0, 0, t.invalid("the right child has a wrong parent", right)
+	}
+	lHeight, lCount, err := t.validateLocation(left, prev)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !prev.isNil() && t.cmp != nil && t.cmp(prev.key(), loc.key()) >= 0 {
+		return 0, 0, t.invalid(fmt.Sprintf("the key is not greater than the previous key %v", prev.key()), loc)
+	}
+	*prev = loc
+	rHeight, rCount, err := t.validateLocation(right, prev)
+	if err != nil {
+		return 0, 0, err
+	}
+	height = 1 + max2(lHeight, rHeight)
+	if height != int(loc.height()) {
+		return 0, 0, t.invalid(fmt.Sprintf("the height must be %d", height), loc)
+	}
+	if b := rHeight - lHeight; b < -1 || b > 1 {
+		return 0, 0, t.invalid(fmt.Sprintf("the balance is %d", b), loc)
+	}
+	if t.options.countChildren && childCount(lCount+rCount) != loc.childrenCount() {
+		return 0, 0, t.invalid(fmt.Sprintf("the children count must be %d", lCount+rCount), loc)
+	}
+	if t.weight != nil {
+		want := t.weight(loc.k, &loc.v) + left.weightSum() + right.weightSum()
+		if want != loc.subtreeWeight() {
+			return 0, 0, t.invalid(fmt.Sprintf("the weight must be %d", want), loc)
+		}
+	}
+	return height, lCount + rCount + 1, nil
+}
+
+func (t *Tree[K, V, Cmp]) invalid(reason string, loc location[K, V]) error {
+	node := "<nil>"
+	if !loc.isNil() {
+		node = loc.String()
+	}
+	return fmt.Errorf("%w: %s: %s", ErrInvalidTree, reason, node)
+}
+
+// checkInvariants panics if paranoid checks are enabled and the tree is invalid.
+func (t *Tree[K, V, Cmp]) checkInvariants() {
+	if !t.options.paranoid {
+		return
+	}
+	if err := t.Validate(); err != nil {
+		panic(err)
+	}
+}
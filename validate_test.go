@@ -0,0 +1,116 @@
+package goavl
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidateTree() *Tree[int, int, func(a, b int) int] {
+	tree := NewComparable[int, int](WithCountChildren(true))
+	for i := 0; i < 31; i++ {
+		tree.Insert(i, i)
+	}
+	return tree
+}
+
+func TestTreeValidate(t *testing.T) {
+	a := assert.New(t)
+	a.NoError(NewComparable[int, int]().Validate())
+	a.NoError(newValidateTree().Validate())
+
+	for name, tc := range map[string]struct {
+		corrupt func(tree *Tree[int, int, func(a, b int) int])
+		reason  string
+	}{
+		"order": {
+			corrupt: func(tree *Tree[int, int, func(a, b int) int]) { tree.root.k = -1 },
+			reason:  "the key is not greater than the previous key",
+		},
+		"height": {
+			corrupt: func(tree *Tree[int, int, func(a, b int) int]) { tree.root.setHeight(10) },
+			reason:  "the height must be",
+		},
+		"balance": {
+			corrupt: func(tree *Tree[int, int, func(a, b int) int]) {
+				right := tree.root.right()
+				tree.root.ptrNode.right = location[int, int]{}
+				tree.length -= 1 + int(right.childrenCount())
+				tree.max = tree.root
+				tree.root.recalcHeight()
+				tree.root.recalcCounts()
+			},
+			reason: "the balance is -",
+		},
+		"counts": {
+			corrupt: func(tree *Tree[int, int, func(a, b int) int]) {
+				left := tree.root.left()
+				left.setChildrenCount(0)
+			},
+			reason: "the children count must be",
+		},
+		"parent": {
+			corrupt: func(tree *Tree[int, int, func(a, b int) int]) {
+				left := tree.root.left()
+				left.setParent(tree.max)
+			},
+			reason: "the left child has a wrong parent",
+		},
+		"root parent": {
+			corrupt: func(tree *Tree[int, int, func(a, b int) int]) { tree.root.setParent(tree.max) },
+			reason:  "the root has a parent",
+		},
+		"min": {
+			corrupt: func(tree *Tree[int, int, func(a, b int) int]) { tree.min = tree.root },
+			reason:  "min doesn't point to the leftmost node",
+		},
+		"max": {
+			corrupt: func(tree *Tree[int, int, func(a, b int) int]) { tree.max = tree.root },
+			reason:  "max doesn't point to the rightmost node",
+		},
+		"length": {
+			corrupt: func(tree *Tree[int, int, func(a, b int) int]) { tree.length++ },
+			reason:  "length is 32, but there are 31 nodes",
+		},
+	} {
+		tree := newValidateTree()
+		tc.corrupt(tree)
+		err := tree.Validate()
+		a.Truef(errors.Is(err, ErrInvalidTree), "%s: %v", name, err)
+		a.ErrorContainsf(err, tc.reason, name)
+	}
+}
+
+func TestTreeParanoidChecks(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int](WithCountChildren(true), WithParanoidChecks(true))
+	for i := 0; i < 64; i++ {
+		tree.Insert(i, i)
+	}
+	tree.UpdateKey(3, 100)
+	tree.Delete(10)
+	tree.DeleteAt(5)
+
+	// a comparator that changes its answers corrupts the tree.
+	reversed := false
+	bad := New[int, int](func(a, b int) int {
+		if reversed {
+			return intCmp(b, a)
+		}
+		return intCmp(a, b)
+	}, WithParanoidChecks(true))
+	for i := 0; i < 16; i++ {
+		bad.Insert(i, i)
+	}
+	reversed = true
+	var err error
+	func() {
+		defer func() {
+			err, _ = recover().(error)
+		}()
+		bad.Insert(100, 100)
+	}()
+	a.True(errors.Is(err, ErrInvalidTree), "%v", err)
+	a.ErrorContains(err, "the key is not greater than the previous key")
+}
@@ -0,0 +1,148 @@
+package goavl
+
+import "fmt"
+
+// WithWeights makes every node of the tree keep the total weight of its subtree,
+// which enables O(logn) SelectByWeight, WeightRank and WeightInRange.
+// The weight of an element must depend only on its key and value.
+// If a value is modified through a pointer, call Reweigh for its key.
+// The total weight of the tree must fit into uint64.
+// Weights require the goavl_weights build tag, which adds 8 bytes to every node,
+// otherwise creating a tree with weights panics.
+func WithWeights[K, V any](weight func(k K, v *V) uint64) Option {
+	return func(o *Options) {
+		o.weight = weight
+	}
+}
+
+func newWeightFunc[K, V any](o any) func(k K, v *V) uint64 {
+	if o == nil {
+		return nil
+	}
+	if !weightsEnabled {
+		panic("goavl: weights require the goavl_weights build tag")
+	}
+	f, ok := o.(func(k K, v *V) uint64)
+	if !ok {
+		panic(fmt.Sprintf("goavl: weight func type %T doesn't match the tree", o))
+	}
+	return f
+}
+
+func (l *location[K, V]) recalcWeight(weight func(k K, v *V) uint64) {
+	l.setSubtreeWeight(weight(l.k, &l.v) + l.left().weightSum() + l.right().weightSum())
+}
+
+func (l location[K, V]) weightSum() uint64 {
+	if l.isNil() {
+		return 0
+	}
+	return l.subtreeWeight()
+}
+
+// ownWeight returns the weight of the element itself.
+func (l *location[K, V]) ownWeight() uint64 {
+	return l.subtreeWeight() - l.left().weightSum() - l.right().weightSum()
+}
+
+// reweigh updates the weights from loc up to the root, if weights are enabled.
+func (t *Tree[K, V, Cmp]) reweigh(loc location[K, V]) {
+	if t.weight != nil {
+		t.updateCounts(loc)
+	}
+}
+
+func (t *Tree[K, V, Cmp]) mustHaveWeights() {
+	if t.weight == nil {
+		panic("goavl: weights are not enabled, use WithWeights")
+	}
+}
+
+// Reweigh recalculates the weight of k after its value was modified through a pointer.
+// Returns false if k is not present.
+// Time complexity: O(logn).
+func (t *Tree[K, V, Cmp]) Reweigh(k K) bool {
+	t.mustHaveWeights()
+	loc, dir := t.locate(k)
+	if dir != dirCenter || loc.isNil() {
+		return false
+	}
+	t.reweigh(loc)
+	return true
+}
+
+// TotalWeight returns the total weight of the elements.
+// Panics if weights are not enabled.
+// Time complexity: O(1).
+func (t *Tree[K, V, Cmp]) TotalWeight() uint64 {
+	t.mustHaveWeights()
+	return t.root.weightSum()
+}
+
+// SelectByWeight returns the first element at which the cumulative weight,
+// including the weight of the element itself, reaches w.
+// For instance, for a random w in [1, TotalWeight()] the elements are selected with probabilities
+// proportional to their weights.
+// Returns false if the tree is empty or w > TotalWeight(). Panics if weights are not enabled.
+// Time complexity: O(logn).
+func (t *Tree[K, V, Cmp]) SelectByWeight(w uint64) (entry Entry[K, V], found bool) {
+	t.mustHaveWeights()
+	if t.root.isNil() || w > t.root.weightSum() {
+		return entry, false
+	}
+	loc := t.root
+	for {
+		left := loc.left()
+		lw := left.weightSum()
+		switch {
+		case !left.isNil() && w <= lw:
+			loc = left
+		case w <= lw+loc.ownWeight():
+			return Entry[K, V]{Key: loc.key(), Value: loc.valuePtr()}, true
+		default:
+			w -= lw + loc.ownWeight()
+			loc = loc.right()
+		}
+	}
+}
+
+// WeightRank returns the total weight of the elements with keys <= k.
+// Panics if weights are not enabled.
+// Time complexity: O(logn).
+func (t *Tree[K, V, Cmp]) WeightRank(k K) uint64 {
+	return t.weightBefore(k, true)
+}
+
+// WeightInRange returns the total weight of the elements on the inclusive interval [lo, hi].
+// Panics if weights are not enabled.
+// Time complexity: O(logn).
+func (t *Tree[K, V, Cmp]) WeightInRange(lo, hi K) uint64 {
+	if t.cmp(lo, hi) > 0 {
+		t.mustHaveWeights()
+		return 0
+	}
+	return t.weightBefore(hi, true) - t.weightBefore(lo, false)
+}
+
+// weightBefore returns the total weight of the elements with keys < k, or <= k, if inclusive is true.
+func (t *Tree[K, V, Cmp]) weightBefore(k K, inclusive bool) uint64 {
+	t.mustHaveWeights()
+	var result uint64
+	loc := t.root
+	for !loc.isNil() {
+		switch cmp := t.cmp(k, loc.key()); {
+		case cmp < 0:
+			loc = loc.left()
+		case cmp == 0:
+			result += loc.left().weightSum()
+			if inclusive {
+				result += loc.ownWeight()
+			}
+			return result
+		default:
+			result += loc.subtreeWeight() - loc.right().weightSum()
+			loc = loc.right()
+		}
+	}
+	return result
+}
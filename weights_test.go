@@ -0,0 +1,133 @@
+//go:build goavl_weights
+
+package goavl
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeWeights(t *testing.T) {
+	a := assert.New(t)
+	tree := NewComparable[int, int](WithWeights(intWeight))
+	a.Zero(tree.TotalWeight())
+	_, found := tree.SelectByWeight(0)
+	a.False(found)
+	for k, w := range map[int]int{10: 5, 20: 0, 30: 10, 40: 1} {
+		tree.Insert(k, w)
+	}
+	a.NoError(tree.Validate())
+	a.Equal(uint64(16), tree.TotalWeight())
+	for w, want := range map[uint64]int{0: 10, 1: 10, 5: 10, 6: 30, 15: 30, 16: 40} {
+		e, found := tree.SelectByWeight(w)
+		a.True(found)
+		a.Equalf(want, e.Key, "w = %d", w)
+	}
+	_, found = tree.SelectByWeight(17)
+	a.False(found)
+
+	a.Equal(uint64(5), tree.WeightRank(20))
+	a.Equal(uint64(5), tree.WeightRank(25))
+	a.Equal(uint64(15), tree.WeightRank(30))
+	a.Equal(uint64(0), tree.WeightRank(5))
+	a.Equal(uint64(10), tree.WeightInRange(20, 30))
+	a.Equal(uint64(11), tree.WeightInRange(11, 100))
+	a.Equal(uint64(0), tree.WeightInRange(30, 20))
+
+	ptr, _ := tree.Find(20)
+	*ptr = 100
+	a.Error(tree.Validate())
+	a.True(tree.Reweigh(20))
+	a.False(tree.Reweigh(21))
+	a.NoError(tree.Validate())
+	a.Equal(uint64(116), tree.TotalWeight())
+
+	a.Panics(func() {
+		NewComparable[int, int]().WeightRank(1)
+	})
+	a.Panics(func() {
+		NewComparable[int, int](WithWeights(func(k int, v *string) uint64 { return 0 }))
+	})
+}
+
+func TestTreeWeightsRandom(t *testing.T) {
+	a := assert.New(t)
+	r := rand.New(rand.NewSource(5))
+	tree := NewComparable[int, int](WithWeights(intWeight), WithCountChildren(true), WithParanoidChecks(true))
+	model := make(map[int]int)
+	for i := 0; i < 2000; i++ {
+		k := r.Intn(200)
+		switch op := r.Intn(10); {
+		case op < 5:
+			v := r.Intn(100)
+			tree.Insert(k, v)
+			model[k] = v
+		case op < 7:
+			tree.Delete(k)
+			delete(model, k)
+		case op < 8:
+			newKey := r.Intn(200)
+			if v, found := model[k]; found {
+				delete(model, k)
+				model[newKey] = v
+			}
+			tree.UpdateKey(k, newKey)
+		case op < 9:
+			if k, _, found := tree.PopMin(); found {
+				delete(model, k)
+			}
+		default:
+			if tree.Len() > 0 {
+				k, _ := tree.DeleteAt(r.Intn(tree.Len()))
+				delete(model, k)
+			}
+		}
+		if i%50 != 0 {
+			continue
+		}
+		keys := make([]int, 0, len(model))
+		var total uint64
+		for k, v := range model {
+			keys = append(keys, k)
+			total += uint64(v)
+		}
+		sort.Ints(keys)
+		a.Equal(total, tree.TotalWeight())
+		lo, hi := r.Intn(200), r.Intn(200)
+		var rank, inRange uint64
+		for _, k := range keys {
+			if k <= hi {
+				rank += uint64(model[k])
+				if k >= lo {
+					inRange += uint64(model[k])
+				}
+			}
+		}
+		a.Equal(rank, tree.WeightRank(hi))
+		if lo <= hi {
+			a.Equal(inRange, tree.WeightInRange(lo, hi))
+		}
+		if total > 0 {
+			w := uint64(r.Int63n(int64(total))) + 1
+			var cum uint64
+			for _, k := range keys {
+				if cum += uint64(model[k]); cum >= w {
+					e, found := tree.SelectByWeight(w)
+					a.True(found)
+					a.Equal(k, e.Key)
+					break
+				}
+			}
+		}
+	}
+
+	data, err := tree.GobEncode()
+	a.NoError(err)
+	decoded := NewComparable[int, int](WithWeights(intWeight))
+	a.NoError(decoded.GobDecode(data))
+	a.NoError(decoded.Validate())
+	a.Equal(tree.TotalWeight(), decoded.TotalWeight())
+}